@@ -21,8 +21,20 @@ type ProjectAPIClient interface {
 	CreateProject(ctx context.Context, in *CreateProjectRequest, opts ...grpc.CallOption) (*CreateProjectResponse, error)
 	GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*GetProjectResponse, error)
 	UpdateProject(ctx context.Context, in *UpdateProjectRequest, opts ...grpc.CallOption) (*UpdateProjectResponse, error)
+	// DeleteProject soft deletes a project, it can be restored with UndeleteProject until its expire_time.
 	DeleteProject(ctx context.Context, in *DeleteProjectRequest, opts ...grpc.CallOption) (*DeleteProjectResponse, error)
+	UndeleteProject(ctx context.Context, in *UndeleteProjectRequest, opts ...grpc.CallOption) (*UndeleteProjectResponse, error)
 	ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error)
+	// WatchProjects streams the changes made to projects, starting after sequence_token when set.
+	// The changes of every project of the tenant are streamed, whatever the memberships of the caller.
+	WatchProjects(ctx context.Context, in *WatchProjectsRequest, opts ...grpc.CallOption) (ProjectAPI_WatchProjectsClient, error)
+	// BatchGetProjects fails as a whole when one of the projects doesn't exist.
+	BatchGetProjects(ctx context.Context, in *BatchGetProjectsRequest, opts ...grpc.CallOption) (*BatchGetProjectsResponse, error)
+	// BatchCreateProjects is all-or-nothing when the storage backend supports transactions,
+	// otherwise every project is created on its own and failures are reported per item.
+	BatchCreateProjects(ctx context.Context, in *BatchCreateProjectsRequest, opts ...grpc.CallOption) (*BatchCreateProjectsResponse, error)
+	// BatchDeleteProjects soft deletes projects, with the same semantics as BatchCreateProjects.
+	BatchDeleteProjects(ctx context.Context, in *BatchDeleteProjectsRequest, opts ...grpc.CallOption) (*BatchDeleteProjectsResponse, error)
 }
 
 type projectAPIClient struct {
@@ -69,6 +81,15 @@ func (c *projectAPIClient) DeleteProject(ctx context.Context, in *DeleteProjectR
 	return out, nil
 }
 
+func (c *projectAPIClient) UndeleteProject(ctx context.Context, in *UndeleteProjectRequest, opts ...grpc.CallOption) (*UndeleteProjectResponse, error) {
+	out := new(UndeleteProjectResponse)
+	err := c.cc.Invoke(ctx, "/platform.v1.ProjectAPI/UndeleteProject", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *projectAPIClient) ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error) {
 	out := new(ListProjectsResponse)
 	err := c.cc.Invoke(ctx, "/platform.v1.ProjectAPI/ListProjects", in, out, opts...)
@@ -78,6 +99,65 @@ func (c *projectAPIClient) ListProjects(ctx context.Context, in *ListProjectsReq
 	return out, nil
 }
 
+func (c *projectAPIClient) WatchProjects(ctx context.Context, in *WatchProjectsRequest, opts ...grpc.CallOption) (ProjectAPI_WatchProjectsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ProjectAPI_ServiceDesc.Streams[0], "/platform.v1.ProjectAPI/WatchProjects", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &projectAPIWatchProjectsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ProjectAPI_WatchProjectsClient interface {
+	Recv() (*WatchProjectsResponse, error)
+	grpc.ClientStream
+}
+
+type projectAPIWatchProjectsClient struct {
+	grpc.ClientStream
+}
+
+func (x *projectAPIWatchProjectsClient) Recv() (*WatchProjectsResponse, error) {
+	m := new(WatchProjectsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *projectAPIClient) BatchGetProjects(ctx context.Context, in *BatchGetProjectsRequest, opts ...grpc.CallOption) (*BatchGetProjectsResponse, error) {
+	out := new(BatchGetProjectsResponse)
+	err := c.cc.Invoke(ctx, "/platform.v1.ProjectAPI/BatchGetProjects", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *projectAPIClient) BatchCreateProjects(ctx context.Context, in *BatchCreateProjectsRequest, opts ...grpc.CallOption) (*BatchCreateProjectsResponse, error) {
+	out := new(BatchCreateProjectsResponse)
+	err := c.cc.Invoke(ctx, "/platform.v1.ProjectAPI/BatchCreateProjects", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *projectAPIClient) BatchDeleteProjects(ctx context.Context, in *BatchDeleteProjectsRequest, opts ...grpc.CallOption) (*BatchDeleteProjectsResponse, error) {
+	out := new(BatchDeleteProjectsResponse)
+	err := c.cc.Invoke(ctx, "/platform.v1.ProjectAPI/BatchDeleteProjects", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProjectAPIServer is the server API for ProjectAPI service.
 // All implementations should embed UnimplementedProjectAPIServer
 // for forward compatibility
@@ -85,8 +165,20 @@ type ProjectAPIServer interface {
 	CreateProject(context.Context, *CreateProjectRequest) (*CreateProjectResponse, error)
 	GetProject(context.Context, *GetProjectRequest) (*GetProjectResponse, error)
 	UpdateProject(context.Context, *UpdateProjectRequest) (*UpdateProjectResponse, error)
+	// DeleteProject soft deletes a project, it can be restored with UndeleteProject until its expire_time.
 	DeleteProject(context.Context, *DeleteProjectRequest) (*DeleteProjectResponse, error)
+	UndeleteProject(context.Context, *UndeleteProjectRequest) (*UndeleteProjectResponse, error)
 	ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error)
+	// WatchProjects streams the changes made to projects, starting after sequence_token when set.
+	// The changes of every project of the tenant are streamed, whatever the memberships of the caller.
+	WatchProjects(*WatchProjectsRequest, ProjectAPI_WatchProjectsServer) error
+	// BatchGetProjects fails as a whole when one of the projects doesn't exist.
+	BatchGetProjects(context.Context, *BatchGetProjectsRequest) (*BatchGetProjectsResponse, error)
+	// BatchCreateProjects is all-or-nothing when the storage backend supports transactions,
+	// otherwise every project is created on its own and failures are reported per item.
+	BatchCreateProjects(context.Context, *BatchCreateProjectsRequest) (*BatchCreateProjectsResponse, error)
+	// BatchDeleteProjects soft deletes projects, with the same semantics as BatchCreateProjects.
+	BatchDeleteProjects(context.Context, *BatchDeleteProjectsRequest) (*BatchDeleteProjectsResponse, error)
 }
 
 // UnimplementedProjectAPIServer should be embedded to have forward compatible implementations.
@@ -105,9 +197,24 @@ func (UnimplementedProjectAPIServer) UpdateProject(context.Context, *UpdateProje
 func (UnimplementedProjectAPIServer) DeleteProject(context.Context, *DeleteProjectRequest) (*DeleteProjectResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteProject not implemented")
 }
+func (UnimplementedProjectAPIServer) UndeleteProject(context.Context, *UndeleteProjectRequest) (*UndeleteProjectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndeleteProject not implemented")
+}
 func (UnimplementedProjectAPIServer) ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListProjects not implemented")
 }
+func (UnimplementedProjectAPIServer) WatchProjects(*WatchProjectsRequest, ProjectAPI_WatchProjectsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchProjects not implemented")
+}
+func (UnimplementedProjectAPIServer) BatchGetProjects(context.Context, *BatchGetProjectsRequest) (*BatchGetProjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetProjects not implemented")
+}
+func (UnimplementedProjectAPIServer) BatchCreateProjects(context.Context, *BatchCreateProjectsRequest) (*BatchCreateProjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateProjects not implemented")
+}
+func (UnimplementedProjectAPIServer) BatchDeleteProjects(context.Context, *BatchDeleteProjectsRequest) (*BatchDeleteProjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeleteProjects not implemented")
+}
 
 // UnsafeProjectAPIServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ProjectAPIServer will
@@ -192,6 +299,24 @@ func _ProjectAPI_DeleteProject_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProjectAPI_UndeleteProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectAPIServer).UndeleteProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/platform.v1.ProjectAPI/UndeleteProject",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectAPIServer).UndeleteProject(ctx, req.(*UndeleteProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProjectAPI_ListProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListProjectsRequest)
 	if err := dec(in); err != nil {
@@ -210,6 +335,81 @@ func _ProjectAPI_ListProjects_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProjectAPI_WatchProjects_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchProjectsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProjectAPIServer).WatchProjects(m, &projectAPIWatchProjectsServer{stream})
+}
+
+type ProjectAPI_WatchProjectsServer interface {
+	Send(*WatchProjectsResponse) error
+	grpc.ServerStream
+}
+
+type projectAPIWatchProjectsServer struct {
+	grpc.ServerStream
+}
+
+func (x *projectAPIWatchProjectsServer) Send(m *WatchProjectsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ProjectAPI_BatchGetProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetProjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectAPIServer).BatchGetProjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/platform.v1.ProjectAPI/BatchGetProjects",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectAPIServer).BatchGetProjects(ctx, req.(*BatchGetProjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProjectAPI_BatchCreateProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateProjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectAPIServer).BatchCreateProjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/platform.v1.ProjectAPI/BatchCreateProjects",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectAPIServer).BatchCreateProjects(ctx, req.(*BatchCreateProjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProjectAPI_BatchDeleteProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteProjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectAPIServer).BatchDeleteProjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/platform.v1.ProjectAPI/BatchDeleteProjects",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectAPIServer).BatchDeleteProjects(ctx, req.(*BatchDeleteProjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProjectAPI_ServiceDesc is the grpc.ServiceDesc for ProjectAPI service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -233,11 +433,33 @@ var ProjectAPI_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteProject",
 			Handler:    _ProjectAPI_DeleteProject_Handler,
 		},
+		{
+			MethodName: "UndeleteProject",
+			Handler:    _ProjectAPI_UndeleteProject_Handler,
+		},
 		{
 			MethodName: "ListProjects",
 			Handler:    _ProjectAPI_ListProjects_Handler,
 		},
+		{
+			MethodName: "BatchGetProjects",
+			Handler:    _ProjectAPI_BatchGetProjects_Handler,
+		},
+		{
+			MethodName: "BatchCreateProjects",
+			Handler:    _ProjectAPI_BatchCreateProjects_Handler,
+		},
+		{
+			MethodName: "BatchDeleteProjects",
+			Handler:    _ProjectAPI_BatchDeleteProjects_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchProjects",
+			Handler:       _ProjectAPI_WatchProjects_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "platform/v1/project.proto",
 }
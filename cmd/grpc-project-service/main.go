@@ -2,12 +2,19 @@ package main
 
 import (
 	"learning/grpc-project-service/internal/controller"
+	"learning/grpc-project-service/internal/repository"
 	"learning/grpc-project-service/internal/router"
 	"learning/grpc-project-service/internal/service"
+	"learning/grpc-project-service/pkg/authz"
 	"learning/grpc-project-service/pkg/provider/app"
+	"learning/grpc-project-service/pkg/provider/broadcast"
 	"learning/grpc-project-service/pkg/provider/grpc"
 	"learning/grpc-project-service/pkg/provider/grpc/gateway"
+	"learning/grpc-project-service/pkg/provider/mongodb"
+	"learning/grpc-project-service/pkg/ratelimit"
 	"learning/grpc-project-service/pkg/stack"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/validator"
 )
 
 func main() {
@@ -19,18 +26,68 @@ func main() {
 	appProvider := app.New(appConfig)
 	st.MustInit(appProvider)
 
+	// repository, initialized before the servers so MongoDB is only disconnected once they are drained.
+	repoConfig := repository.NewConfigFromEnv()
+	var mongodbProvider *mongodb.MongoDB
+	if repoConfig.Backend == repository.BackendMongoDB {
+		mongodbProvider = mongodb.New(mongodb.NewConfigFromEnv())
+		st.MustInit(mongodbProvider)
+	}
+	repo := repository.NewProvider(repoConfig, mongodbProvider)
+	st.MustInit(repo)
+
 	// grpc
 	grpcConfig := grpc.NewConfigFromEnv()
-	grpcProvider := grpc.New(grpcConfig)
+	// GitHub signs its webhooks instead of sending a token, ReceiveGitHubEvent verifies the signature itself.
+	grpcConfig.Auth.UnauthenticatedMethods = append(grpcConfig.Auth.UnauthenticatedMethods, "/platform.v1.GitHubAPI/ReceiveGitHubEvent")
+	// The tenant of the requests is resolved before they are authorized, project roles are looked for in it.
+	tenantResolver := tenant.New(tenant.NewConfigFromEnv())
+	st.MustInit(tenantResolver)
+	// Project roles of the policy are resolved by the service, set once it is created.
+	authorizer := authz.New(authz.NewConfigFromEnv())
+	// These methods return every project of the tenant, project roles can't protect them: ListProjects isn't filtered
+	// by membership, WatchProjects streams the changes of all projects and webhooks are sent them too.
+	authorizer.Config.TenantWideMethods = append(authorizer.Config.TenantWideMethods,
+		"/platform.v1.ProjectAPI/ListProjects",
+		"/platform.v1.ProjectAPI/WatchProjects",
+		"/platform.v1.WebhookAPI/",
+	)
+	st.MustInit(authorizer)
+	// Callers are limited once authenticated, so their identity tells them apart.
+	rateLimiter := ratelimit.New(ratelimit.NewConfigFromEnv())
+	st.MustInit(rateLimiter)
+	// Requests are validated against their buf.validate annotations before they are authorized,
+	// a malformed project_id is reported as such instead of a denial.
+	validate := validator.New()
+	validate.InvalidArgument = controller.InvalidArgument
+	st.MustInit(validate)
+	grpcProvider := grpc.New(grpcConfig, tenantResolver.CustomOpts(), rateLimiter.CustomOpts(), validate.CustomOpts(), authorizer.CustomOpts())
 	st.MustInit(grpcProvider)
 
 	// grpc-gateway
 	gatewayConfig := gateway.NewConfigFromEnv()
 	gatewayProvider := gateway.New(gatewayConfig, grpcProvider, appProvider)
+	gatewayProvider.RateLimiter = rateLimiter
 	st.MustInit(gatewayProvider)
 
-	svc := service.New()
+	// Change feed of WatchProjects. Initialized after the servers so it is closed first,
+	// ending the open streams that would otherwise block their graceful shutdown.
+	broadcaster := broadcast.New(broadcast.NewConfigFromEnv())
+	st.MustInit(broadcaster)
+
+	svcConfig := service.NewConfigFromEnv()
+	svcConfig.AuthEnabled = grpcConfig.Auth.Enabled
+	svcConfig.RolesClaim = authorizer.Config.RolesClaim
+	svc := service.New(svcConfig, repo, broadcaster)
 	st.MustInit(svc)
+	authorizer.Roles = svc
+	grpcProvider.Authenticator.ApiKeys = svc
+
+	// Removes soft deleted projects once they expire.
+	st.MustInit(service.NewPurger(svcConfig, repo))
+
+	// Delivers project changes to the registered webhooks.
+	st.MustInit(service.NewDispatcher(svcConfig, repo, broadcaster))
 
 	rt := router.NewRouter(grpcProvider, gatewayProvider, controller.New(svc))
 	st.MustInit(rt)
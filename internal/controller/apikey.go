@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+type ApiKeyController interface {
+	pb.ApiKeyAPIServer
+}
+
+func (c controller) CreateApiKey(ctx context.Context, req *pb.CreateApiKeyRequest) (*pb.CreateApiKeyResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::CreateApiKey")
+	defer span.Finish()
+
+	return c.service.CreateApiKey(ctx, req)
+}
+
+func (c controller) ListApiKeys(ctx context.Context, req *pb.ListApiKeysRequest) (*pb.ListApiKeysResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::ListApiKeys")
+	defer span.Finish()
+
+	return c.service.ListApiKeys(ctx, req)
+}
+
+func (c controller) RotateApiKey(ctx context.Context, req *pb.RotateApiKeyRequest) (*pb.RotateApiKeyResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::RotateApiKey")
+	defer span.Finish()
+
+	return c.service.RotateApiKey(ctx, req)
+}
+
+func (c controller) RevokeApiKey(ctx context.Context, req *pb.RevokeApiKeyRequest) (*pb.RevokeApiKeyResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::RevokeApiKey")
+	defer span.Finish()
+
+	return c.service.RevokeApiKey(ctx, req)
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+func (c controller) BatchGetProjects(ctx context.Context, req *pb.BatchGetProjectsRequest) (*pb.BatchGetProjectsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::BatchGetProjects")
+	defer span.Finish()
+
+	return c.service.BatchGetProjects(ctx, req)
+}
+
+func (c controller) BatchCreateProjects(ctx context.Context, req *pb.BatchCreateProjectsRequest) (*pb.BatchCreateProjectsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::BatchCreateProjects")
+	defer span.Finish()
+
+	return c.service.BatchCreateProjects(ctx, req)
+}
+
+func (c controller) BatchDeleteProjects(ctx context.Context, req *pb.BatchDeleteProjectsRequest) (*pb.BatchDeleteProjectsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::BatchDeleteProjects")
+	defer span.Finish()
+
+	return c.service.BatchDeleteProjects(ctx, req)
+}
@@ -7,6 +7,11 @@ import (
 type (
 	Controller interface {
 		ProjectController
+		WebhookController
+		GitHubController
+		MemberController
+		ApiKeyController
+		QuotaController
 	}
 
 	controller struct {
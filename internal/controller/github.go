@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc/metadata"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/validator"
+)
+
+type GitHubController interface {
+	pb.GitHubAPIServer
+}
+
+func (c controller) ReceiveGitHubEvent(ctx context.Context, req *pb.ReceiveGitHubEventRequest) (*pb.ReceiveGitHubEventResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::ReceiveGitHubEvent")
+	defer span.Finish()
+
+	// The gateway forwards the GitHub headers as metadata.
+	md, _ := metadata.FromIncomingContext(ctx)
+	fromHeader := func(field *string, key string) {
+		if values := md.Get(key); len(*field) == 0 && len(values) > 0 {
+			*field = values[0]
+		}
+	}
+	fromHeader(&req.Event, "x-github-event")
+	fromHeader(&req.DeliveryId, "x-github-delivery")
+	fromHeader(&req.Signature, "x-hub-signature-256")
+
+	// The other fields were validated against their annotations, before the headers could be looked at.
+	var violations []*validator.Violation
+	for _, field := range []struct{ path, value string }{{"event", req.Event}, {"delivery_id", req.DeliveryId}} {
+		if len(field.value) == 0 {
+			violations = append(violations, &validator.Violation{Field: field.path, ConstraintID: "required", Message: "value is required"})
+		}
+	}
+	if len(violations) > 0 {
+		return nil, InvalidArgument(ctx, violations)
+	}
+
+	return c.service.ReceiveGitHubEvent(ctx, req)
+}
+
+func (c controller) RotateGitHubWebhookSecret(ctx context.Context, req *pb.RotateGitHubWebhookSecretRequest) (*pb.RotateGitHubWebhookSecretResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::RotateGitHubWebhookSecret")
+	defer span.Finish()
+
+	return c.service.RotateGitHubWebhookSecret(ctx, req)
+}
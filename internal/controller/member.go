@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+type MemberController interface {
+	pb.MemberAPIServer
+}
+
+func (c controller) AddMember(ctx context.Context, req *pb.AddMemberRequest) (*pb.AddMemberResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::AddMember")
+	defer span.Finish()
+
+	return c.service.AddMember(ctx, req)
+}
+
+func (c controller) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::RemoveMember")
+	defer span.Finish()
+
+	return c.service.RemoveMember(ctx, req)
+}
+
+func (c controller) UpdateMemberRole(ctx context.Context, req *pb.UpdateMemberRoleRequest) (*pb.UpdateMemberRoleResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::UpdateMemberRole")
+	defer span.Finish()
+
+	return c.service.UpdateMemberRole(ctx, req)
+}
+
+func (c controller) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::ListMembers")
+	defer span.Finish()
+
+	return c.service.ListMembers(ctx, req)
+}
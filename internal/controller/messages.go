@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"golang.org/x/text/language"
+)
+
+// supportedLocales languages of the validation messages, the first one is the default.
+var supportedLocales = []language.Tag{language.English, language.French, language.German}
+
+var localeMatcher = language.NewMatcher(supportedLocales)
+
+// validationMessages translations of the violations, indexed by locale then constraint id.
+// English messages are the ones of protovalidate, templates take the rules of the violated constraint, eg: {{.max_len}}.
+var validationMessages = map[language.Tag]map[string]string{
+	language.French: {
+		"required":           "la valeur est obligatoire",
+		"string.uuid":        "la valeur doit être un UUID valide",
+		"string.min_len":     "la valeur doit contenir au moins {{.min_len}} caractères",
+		"string.max_len":     "la valeur ne doit pas dépasser {{.max_len}} caractères",
+		"string.pattern":     "la valeur ne correspond pas à l'expression régulière `{{.pattern}}`",
+		"int64.gte":          "la valeur doit être supérieure ou égale à {{.gte}}",
+		"int64.gte_lte":      "la valeur doit être comprise entre {{.gte}} et {{.lte}}",
+		"enum.defined_only":  "la valeur doit être une des valeurs de l'énumération",
+		"enum.not_in":        "la valeur ne doit pas être dans la liste {{.not_in}}",
+		"repeated.min_items": "la liste doit contenir au moins {{.min_items}} élément(s)",
+		"map.max_pairs":      "la table ne doit pas dépasser {{.max_pairs}} entrées",
+		"timestamp.gt_now":   "la valeur doit être dans le futur",
+		"http_url":           "la valeur doit être une URL http ou https",
+		"uuid_or_wildcard":   "la valeur doit être un UUID valide ou -",
+	},
+	language.German: {
+		"required":           "der Wert ist erforderlich",
+		"string.uuid":        "der Wert muss eine gültige UUID sein",
+		"string.min_len":     "der Wert muss mindestens {{.min_len}} Zeichen lang sein",
+		"string.max_len":     "der Wert darf höchstens {{.max_len}} Zeichen lang sein",
+		"string.pattern":     "der Wert entspricht nicht dem regulären Ausdruck `{{.pattern}}`",
+		"int64.gte":          "der Wert darf nicht kleiner als {{.gte}} sein",
+		"int64.gte_lte":      "der Wert muss zwischen {{.gte}} und {{.lte}} liegen",
+		"enum.defined_only":  "der Wert muss einer der Werte der Aufzählung sein",
+		"enum.not_in":        "der Wert darf nicht in der Liste {{.not_in}} sein",
+		"repeated.min_items": "die Liste muss mindestens {{.min_items}} Element(e) enthalten",
+		"map.max_pairs":      "die Tabelle darf höchstens {{.max_pairs}} Einträge enthalten",
+		"timestamp.gt_now":   "der Wert muss in der Zukunft liegen",
+		"http_url":           "der Wert muss eine http- oder https-URL sein",
+		"uuid_or_wildcard":   "der Wert muss eine gültige UUID oder - sein",
+	},
+}
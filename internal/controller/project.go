@@ -2,14 +2,17 @@ package controller
 
 import (
 	"context"
-	"google.golang.org/grpc/codes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
-	"github.com/go-ozzo/ozzo-validation/is"
-	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/opentracing/opentracing-go"
 
-	"google.golang.org/grpc/status"
 	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/util"
 )
 
 type ProjectController interface {
@@ -20,26 +23,26 @@ func (c controller) CreateProject(ctx context.Context, req *pb.CreateProjectRequ
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::CreateProject")
 	defer span.Finish()
 
-	err := validation.ValidateStruct(req, validation.Field(&req.Name, validation.Required))
-	if err != nil {
-		return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+	if len(req.RequestId) == 0 {
+		requestID, err := idempotencyKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.RequestId = requestID
 	}
 
-	return c.service.CreateProject(ctx, req)
+	resp, err := c.service.CreateProject(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	setETag(ctx, resp.GetProject().GetEtag())
+	return resp, nil
 }
 
 func (c controller) ListProjects(ctx context.Context, req *pb.ListProjectsRequest) (*pb.ListProjectsResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::ListProjects")
 	defer span.Finish()
 
-	err := validation.ValidateStruct(req,
-		validation.Field(&req.Offset, validation.Min(0)),
-		validation.Field(&req.Limit, validation.Min(0), validation.Max(100)),
-	)
-	if err != nil {
-		return nil, status.New(codes.InvalidArgument, err.Error()).Err()
-	}
-
 	return c.service.ListProjects(ctx, req)
 }
 
@@ -47,34 +50,123 @@ func (c controller) GetProject(ctx context.Context, req *pb.GetProjectRequest) (
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::GetProject")
 	defer span.Finish()
 
-	err := validation.ValidateStruct(req, validation.Field(&req.ProjectId, validation.Required, is.UUID))
+	resp, err := c.service.GetProject(ctx, req)
 	if err != nil {
-		return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+		return nil, err
 	}
-
-	return c.service.GetProject(ctx, req)
+	setETag(ctx, resp.GetProject().GetEtag())
+	return resp, nil
 }
 
 func (c controller) UpdateProject(ctx context.Context, req *pb.UpdateProjectRequest) (*pb.UpdateProjectResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::UpdateProject")
 	defer span.Finish()
 
-	err := validation.ValidateStruct(req, validation.Field(&req.ProjectId, validation.Required, is.UUID))
-	if err != nil {
-		return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+	if etag := ifMatch(ctx); len(etag) > 0 && len(req.GetProject().GetEtag()) == 0 {
+		if req.Project == nil {
+			req.Project = new(pb.Project)
+		}
+		req.Project.Etag = etag
 	}
 
-	return c.service.UpdateProject(ctx, req)
+	resp, err := c.service.UpdateProject(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	setETag(ctx, resp.GetProject().GetEtag())
+	return resp, nil
 }
 
 func (c controller) DeleteProject(ctx context.Context, req *pb.DeleteProjectRequest) (*pb.DeleteProjectResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::DeleteProject")
 	defer span.Finish()
 
-	err := validation.ValidateStruct(req, validation.Field(&req.ProjectId, validation.Required, is.UUID))
+	if len(req.Etag) == 0 {
+		req.Etag = ifMatch(ctx)
+	}
+
+	resp, err := c.service.DeleteProject(ctx, req)
 	if err != nil {
-		return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+		return nil, err
 	}
+	setETag(ctx, resp.GetProject().GetEtag())
+	return resp, nil
+}
+
+func (c controller) UndeleteProject(ctx context.Context, req *pb.UndeleteProjectRequest) (*pb.UndeleteProjectResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::UndeleteProject")
+	defer span.Finish()
+
+	if len(req.Etag) == 0 {
+		req.Etag = ifMatch(ctx)
+	}
+
+	resp, err := c.service.UndeleteProject(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	setETag(ctx, resp.GetProject().GetEtag())
+	return resp, nil
+}
 
-	return c.service.DeleteProject(ctx, req)
+func (c controller) WatchProjects(req *pb.WatchProjectsRequest, stream pb.ProjectAPI_WatchProjectsServer) error {
+	span, ctx := opentracing.StartSpanFromContext(stream.Context(), "Controller::WatchProjects")
+	defer span.Finish()
+
+	return c.service.WatchProjects(req, &watchProjectsServer{ProjectAPI_WatchProjectsServer: stream, ctx: ctx})
+}
+
+// watchProjectsServer carries the context of the controller span down to the service.
+type watchProjectsServer struct {
+	pb.ProjectAPI_WatchProjectsServer
+	ctx context.Context
+}
+
+func (s *watchProjectsServer) Context() context.Context {
+	return s.ctx
+}
+
+// maxIdempotencyKeyLength same as the max_len of the request_id field, the header isn't checked by its annotations.
+const maxIdempotencyKeyLength = 128
+
+// idempotencyKey returns the Idempotency-Key header forwarded by the gateway, empty when the header is missing.
+func idempotencyKey(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("idempotency-key")
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	key := strings.TrimSpace(values[0])
+	if len(key) > maxIdempotencyKeyLength {
+		return "", util.FieldViolation("request_id", fmt.Sprintf("Idempotency-Key must be at most %d characters long", maxIdempotencyKeyLength))
+	}
+	return key, nil
+}
+
+// ifMatch returns the etag of the If-Match header forwarded by the gateway, empty when the header is missing or "*".
+// Only a single etag is supported, weak etags are compared as strong ones.
+func ifMatch(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("if-match")
+	if len(values) == 0 {
+		return ""
+	}
+
+	etag := strings.TrimPrefix(strings.TrimSpace(values[0]), "W/")
+	if etag == "*" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(etag); err == nil {
+		return unquoted
+	}
+	return etag
+}
+
+// setETag sends the etag as response header, the gateway exposes it as the ETag HTTP header.
+func setETag(ctx context.Context, etag string) {
+	if len(etag) == 0 {
+		return
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("etag", strconv.Quote(etag)))
 }
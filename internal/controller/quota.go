@@ -0,0 +1,20 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+type QuotaController interface {
+	pb.QuotaAPIServer
+}
+
+func (c controller) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::GetQuota")
+	defer span.Finish()
+
+	return c.service.GetQuota(ctx, req)
+}
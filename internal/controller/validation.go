@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"learning/grpc-project-service/pkg/util"
+	"learning/grpc-project-service/pkg/validator"
+)
+
+// InvalidArgument converts the violations of a request into an InvalidArgument status, it is the
+// validator.InvalidArgumentFunc of the requests rejected before reaching the controllers.
+// The violations become a google.rpc.BadRequest detail with one violation per invalid field, identified by its proto
+// field path, eg: requests[1].owner_id, and described in the language accepted by the caller.
+// The localized summary is attached as a google.rpc.LocalizedMessage, the status message stays in English.
+func InvalidArgument(ctx context.Context, violations []*validator.Violation) error {
+	locale := requestLocale(ctx)
+	english := make([]*errdetails.BadRequest_FieldViolation, 0, len(violations))
+	localized := make([]*errdetails.BadRequest_FieldViolation, 0, len(violations))
+	for _, violation := range violations {
+		english = append(english, &errdetails.BadRequest_FieldViolation{Field: violation.Field, Description: violation.Message})
+		localized = append(localized, &errdetails.BadRequest_FieldViolation{Field: violation.Field, Description: localize(violation, locale)})
+	}
+
+	message := &errdetails.LocalizedMessage{Locale: locale.String(), Message: util.SummarizeViolations(localized)}
+	return util.BadRequest(util.SummarizeViolations(english), localized, message)
+}
+
+// localize returns the message of a violation in the given locale. Violations without a translation keep their English message.
+func localize(violation *validator.Violation, locale language.Tag) string {
+	message, ok := validationMessages[locale][violation.ConstraintID]
+	if !ok {
+		return violation.Message
+	}
+
+	tmpl, err := template.New(violation.ConstraintID).Parse(message)
+	if err != nil {
+		return violation.Message
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, violation.Params); err != nil {
+		return violation.Message
+	}
+	return b.String()
+}
+
+// requestLocale returns the supported locale best matching the accept-language metadata, English by default.
+func requestLocale(ctx context.Context) language.Tag {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tags, _, err := language.ParseAcceptLanguage(strings.Join(md.Get("accept-language"), ","))
+	if err != nil || len(tags) == 0 {
+		return supportedLocales[0]
+	}
+	_, index, _ := localeMatcher.Match(tags...)
+	return supportedLocales[index]
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+type WebhookController interface {
+	pb.WebhookAPIServer
+}
+
+func (c controller) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::CreateWebhook")
+	defer span.Finish()
+
+	return c.service.CreateWebhook(ctx, req)
+}
+
+func (c controller) GetWebhook(ctx context.Context, req *pb.GetWebhookRequest) (*pb.GetWebhookResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::GetWebhook")
+	defer span.Finish()
+
+	return c.service.GetWebhook(ctx, req)
+}
+
+func (c controller) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::ListWebhooks")
+	defer span.Finish()
+
+	return c.service.ListWebhooks(ctx, req)
+}
+
+func (c controller) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::DeleteWebhook")
+	defer span.Finish()
+
+	return c.service.DeleteWebhook(ctx, req)
+}
+
+func (c controller) ListDeliveries(ctx context.Context, req *pb.ListDeliveriesRequest) (*pb.ListDeliveriesResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Controller::ListDeliveries")
+	defer span.Finish()
+
+	return c.service.ListDeliveries(ctx, req)
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// API key values look like pk_<8 chars prefix>_<secret>, the prefix is stored in clear to find the key.
+const (
+	apiKeyScheme       = "pk_"
+	apiKeyPrefixLength = len(apiKeyScheme) + 8
+	apiKeySecretSize   = 32
+)
+
+var apiKeyPrefixEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+type ApiKey struct {
+	ID           uuid.UUID  `bson:"_id"`
+	TenantID     string     `bson:"tenantId,omitempty"`
+	Prefix       string     `bson:"prefix"`
+	Hash         string     `bson:"hash"` // Hex SHA-256 of the key value, see HashApiKey.
+	DisplayName  string     `bson:"displayName"`
+	Scopes       []string   `bson:"scopes"`
+	Owner        string     `bson:"owner"`
+	CreateTime   time.Time  `bson:"createTime"`
+	ExpireTime   *time.Time `bson:"expireTime,omitempty"`
+	LastUsedTime *time.Time `bson:"lastUsedTime,omitempty"`
+	RotateTime   *time.Time `bson:"rotateTime,omitempty"`
+	RevokeTime   *time.Time `bson:"revokeTime,omitempty"`
+}
+
+// NewApiKey returns the key and its value, only its hash is stored.
+func NewApiKey(req *pb.CreateApiKeyRequest, owner string) (*ApiKey, string, error) {
+	prefix, value, err := newApiKeyValue()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &ApiKey{
+		ID:          uuid.NewV4(),
+		Prefix:      prefix,
+		Hash:        HashApiKey(value),
+		DisplayName: req.GetDisplayName(),
+		Scopes:      req.GetScopes(),
+		Owner:       owner,
+		CreateTime:  time.Now().UTC(),
+	}
+	if key.Scopes == nil {
+		key.Scopes = make([]string, 0)
+	}
+	if req.GetExpireTime() != nil {
+		expireTime := req.GetExpireTime().AsTime().UTC()
+		key.ExpireTime = &expireTime
+	}
+	return key, value, nil
+}
+
+func newApiKeyValue() (prefix string, value string, err error) {
+	raw := make([]byte, 5+apiKeySecretSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	prefix = apiKeyScheme + apiKeyPrefixEncoding.EncodeToString(raw[:5])
+	return prefix, prefix + "_" + base64.RawURLEncoding.EncodeToString(raw[5:]), nil
+}
+
+// HashApiKey returns the stored form of a key value. Values are random, a fast hash doesn't make them guessable.
+func HashApiKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// ApiKeyPrefix returns the prefix of a key value, false when it isn't shaped like a key.
+func ApiKeyPrefix(value string) (string, bool) {
+	if !strings.HasPrefix(value, apiKeyScheme) || len(value) <= apiKeyPrefixLength || value[apiKeyPrefixLength] != '_' {
+		return "", false
+	}
+	return value[:apiKeyPrefixLength], true
+}
+
+// Verify returns whether the value is the one of the key, and the key can still be used.
+func (k *ApiKey) Verify(value string, now time.Time) bool {
+	if subtle.ConstantTimeCompare([]byte(HashApiKey(value)), []byte(k.Hash)) != 1 {
+		return false
+	}
+	return k.RevokeTime == nil && (k.ExpireTime == nil || now.Before(*k.ExpireTime))
+}
+
+// NewApiKeyRotation returns the MongoDB update replacing the value of a key, and the new value.
+func NewApiKeyRotation() (bson.M, string, error) {
+	prefix, value, err := newApiKeyValue()
+	if err != nil {
+		return nil, "", err
+	}
+	return util.WithUpdate(bson.M{
+		"prefix":     prefix,
+		"hash":       HashApiKey(value),
+		"rotateTime": time.Now().UTC(),
+	}), value, nil
+}
+
+// NewApiKeyRevoke returns the MongoDB update revoking a key.
+func NewApiKeyRevoke() bson.M {
+	return util.WithUpdate(bson.M{"revokeTime": time.Now().UTC()})
+}
+
+// NewApiKeyUse returns the MongoDB update recording that a key authenticated a request.
+func NewApiKeyUse(now time.Time) bson.M {
+	return util.WithUpdate(bson.M{"lastUsedTime": now})
+}
+
+// ToPrincipal returns the caller authenticated by the key, it acts as its owner.
+func (k *ApiKey) ToPrincipal() *auth.Principal {
+	principal := &auth.Principal{
+		Subject:  k.Owner,
+		Scopes:   k.Scopes,
+		ApiKeyID: k.ID.String(),
+		Tenant:   k.TenantID,
+		Claims:   make(map[string]interface{}),
+	}
+	if k.ExpireTime != nil {
+		principal.ExpireTime = *k.ExpireTime
+	}
+	return principal
+}
+
+// ToAPI never returns the hash.
+func (k *ApiKey) ToAPI() *pb.ApiKey {
+	key := &pb.ApiKey{
+		Id:          k.ID.String(),
+		DisplayName: k.DisplayName,
+		Prefix:      k.Prefix,
+		Scopes:      k.Scopes,
+		Owner:       k.Owner,
+		CreateTime:  timestamppb.New(k.CreateTime),
+	}
+	if k.ExpireTime != nil {
+		key.ExpireTime = timestamppb.New(*k.ExpireTime)
+	}
+	if k.LastUsedTime != nil {
+		key.LastUsedTime = timestamppb.New(*k.LastUsedTime)
+	}
+	if k.RotateTime != nil {
+		key.RotateTime = timestamppb.New(*k.RotateTime)
+	}
+	if k.RevokeTime != nil {
+		key.RevokeTime = timestamppb.New(*k.RevokeTime)
+	}
+	return key
+}
+
+func (k *ApiKey) ToCreateApiKeyResponse(value string) (*pb.CreateApiKeyResponse, error) {
+	return &pb.CreateApiKeyResponse{ApiKey: k.ToAPI(), Key: value}, nil
+}
+
+func (k *ApiKey) ToRotateApiKeyResponse(value string) (*pb.RotateApiKeyResponse, error) {
+	return &pb.RotateApiKeyResponse{ApiKey: k.ToAPI(), Key: value}, nil
+}
+
+func (k *ApiKey) ToRevokeApiKeyResponse() (*pb.RevokeApiKeyResponse, error) {
+	return &pb.RevokeApiKeyResponse{ApiKey: k.ToAPI()}, nil
+}
+
+func ToListApiKeysResponse(keys []*ApiKey) (*pb.ListApiKeysResponse, error) {
+	elements := make([]*pb.ApiKey, 0, len(keys))
+	for _, key := range keys {
+		elements = append(elements, key.ToAPI())
+	}
+	return &pb.ListApiKeysResponse{Elements: elements}, nil
+}
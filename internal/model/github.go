@@ -0,0 +1,192 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// GitHubRepository repository linked to a project by its GitHub webhook.
+type GitHubRepository struct {
+	ID            int64      `bson:"id"`
+	FullName      string     `bson:"fullName"`
+	HTMLURL       string     `bson:"htmlUrl"`
+	DefaultBranch string     `bson:"defaultBranch"`
+	LinkTime      time.Time  `bson:"linkTime"`
+	PushTime      *time.Time `bson:"pushTime,omitempty"`
+	PushRef       string     `bson:"pushRef,omitempty"`
+	HeadCommit    string     `bson:"headCommit,omitempty"`
+}
+
+func (r *GitHubRepository) ToAPI() *pb.GitHubRepository {
+	repository := &pb.GitHubRepository{
+		Id:            r.ID,
+		FullName:      r.FullName,
+		HtmlUrl:       r.HTMLURL,
+		DefaultBranch: r.DefaultBranch,
+		LinkTime:      timestamppb.New(r.LinkTime),
+		PushRef:       r.PushRef,
+		HeadCommit:    r.HeadCommit,
+	}
+	if r.PushTime != nil {
+		repository.PushTime = timestamppb.New(*r.PushTime)
+	}
+	return repository
+}
+
+// GitHubEvent fields of the GitHub webhook payloads used to link repositories, see https://docs.github.com/en/webhooks/webhook-events-and-payloads.
+type GitHubEvent struct {
+	Action     string `json:"action"`
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository *struct {
+		ID            int64  `json:"id"`
+		FullName      string `json:"full_name"`
+		HTMLURL       string `json:"html_url"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+}
+
+func ParseGitHubEvent(payload []byte) (*GitHubEvent, error) {
+	event := new(GitHubEvent)
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ApplyGitHubEvent links, updates or unlinks the repository of an event, returns false when the event is ignored.
+// Only ping, push and repository events are handled, a repository deleted on GitHub is unlinked.
+func (p *Project) ApplyGitHubEvent(name string, event *GitHubEvent, now time.Time) bool {
+	if event.Repository == nil || event.Repository.ID == 0 {
+		return false
+	}
+	switch name {
+	case "ping", "push", "repository":
+	default:
+		return false
+	}
+
+	index := -1
+	for i, repository := range p.GitHubRepositories {
+		if repository.ID == event.Repository.ID {
+			index = i
+			break
+		}
+	}
+
+	if name == "repository" && event.Action == "deleted" {
+		if index < 0 {
+			return false
+		}
+		p.GitHubRepositories = append(p.GitHubRepositories[:index], p.GitHubRepositories[index+1:]...)
+		return true
+	}
+
+	if index < 0 {
+		p.GitHubRepositories = append(p.GitHubRepositories, GitHubRepository{ID: event.Repository.ID, LinkTime: now})
+		index = len(p.GitHubRepositories) - 1
+	}
+	repository := &p.GitHubRepositories[index]
+	repository.FullName = event.Repository.FullName
+	repository.HTMLURL = event.Repository.HTMLURL
+	repository.DefaultBranch = event.Repository.DefaultBranch
+	if name == "push" {
+		repository.PushTime = &now
+		repository.PushRef = event.Ref
+		repository.HeadCommit = event.After
+	}
+	return true
+}
+
+// NewGitHubRepositoriesUpdate returns the MongoDB update document replacing the linked repositories of a project.
+func NewGitHubRepositoriesUpdate(repositories []GitHubRepository) bson.M {
+	if repositories == nil {
+		repositories = []GitHubRepository{}
+	}
+	return util.WithUpdate(bson.M{
+		"githubRepositories": repositories,
+		"updateTime":         time.Now().UTC(),
+		"etag":               NewETag(),
+	})
+}
+
+// NewGitHubWebhookSecret returns a random webhook secret for a project, with the update document storing it.
+// Only the signatures depend on it, the project keeps its update time and etag.
+func NewGitHubWebhookSecret() (string, bson.M, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+	return secret, util.WithUpdate(bson.M{"githubWebhookSecret": secret}), nil
+}
+
+// GitHubWebhookPath returns the gateway path of the GitHub webhook of a project, scoped to its tenant when it has one.
+func GitHubWebhookPath(tenantID string, projectID uuid.UUID) string {
+	path := "/projects/" + projectID.String() + "/github/webhook"
+	if len(tenantID) > 0 {
+		path = "/tenants/" + url.PathEscape(tenantID) + path
+	}
+	return path
+}
+
+func ToRotateGitHubWebhookSecretResponse(secret string, webhookPath string) (*pb.RotateGitHubWebhookSecretResponse, error) {
+	return &pb.RotateGitHubWebhookSecretResponse{Secret: secret, WebhookPath: webhookPath}, nil
+}
+
+// GitHubDelivery is kept for every GitHub payload received, to reject replays.
+// Signatures cover neither the delivery id nor a timestamp, so deliveries are identified by their payload:
+// a captured payload sent again under a new delivery id is still recognized.
+type GitHubDelivery struct {
+	ID          string    `bson:"_id"` // See GitHubDeliveryID.
+	TenantID    string    `bson:"tenantId,omitempty"`
+	ProjectID   uuid.UUID `bson:"projectId"`
+	DeliveryID  string    `bson:"deliveryId"` // X-Github-Delivery, kept to trace deliveries.
+	Event       string    `bson:"event"`
+	ReceiveTime time.Time `bson:"receiveTime"`
+}
+
+// GitHubDeliveryID returns the hex SHA-256 of the project id followed by the payload.
+// A payload identical to one already received describes the same change, rejecting it loses nothing.
+func GitHubDeliveryID(projectID uuid.UUID, payload []byte) string {
+	hash := sha256.New()
+	hash.Write(projectID.Bytes())
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func NewGitHubDelivery(projectID uuid.UUID, req *pb.ReceiveGitHubEventRequest) *GitHubDelivery {
+	return &GitHubDelivery{
+		ID:          GitHubDeliveryID(projectID, req.GetPayload()),
+		ProjectID:   projectID,
+		DeliveryID:  req.GetDeliveryId(),
+		Event:       req.GetEvent(),
+		ReceiveTime: time.Now().UTC(),
+	}
+}
+
+// ToReceiveGitHubEventResponse returns the repository of the event as linked to the project, only its link is exposed
+// to the webhook caller.
+func (p *Project) ToReceiveGitHubEventResponse(ignored bool, repositoryID int64) (*pb.ReceiveGitHubEventResponse, error) {
+	resp := &pb.ReceiveGitHubEventResponse{Ignored: ignored}
+	if ignored {
+		return resp, nil
+	}
+	for i := range p.GitHubRepositories {
+		if p.GitHubRepositories[i].ID == repositoryID {
+			resp.Repository = p.GitHubRepositories[i].ToAPI()
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/proto"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// IdempotencyRecord is kept for every CreateProject made with a request id, to return the same response when it is retried.
+// It is stored before the project is created, so concurrent retries find it in progress instead of creating another project.
+type IdempotencyRecord struct {
+	ID          string    `bson:"_id"` // See IdempotencyRecordID.
+	TenantID    string    `bson:"tenantId,omitempty"`
+	RequestHash string    `bson:"requestHash"`        // Of the request without its request id, the id can't be reused for another request.
+	Response    []byte    `bson:"response,omitempty"` // Serialized pb.CreateProjectResponse, without the resolved owner name. Empty while in progress.
+	CreateTime  time.Time `bson:"createTime"`
+}
+
+// IdempotencyRecordID returns the id of the record of a request id, request ids of distinct tenants and callers don't collide.
+func IdempotencyRecordID(tenantID, caller, requestID string) string {
+	h := sha256.New()
+	for _, part := range []string{tenantID, caller, requestID} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewIdempotencyRecord returns the record of a CreateProject request in progress, see NewIdempotencyRecordResponse.
+func NewIdempotencyRecord(id string, req *pb.CreateProjectRequest) (*IdempotencyRecord, error) {
+	requestHash, err := HashCreateProjectRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return &IdempotencyRecord{
+		ID:          id,
+		RequestHash: requestHash,
+		CreateTime:  time.Now().UTC(),
+	}, nil
+}
+
+// NewIdempotencyRecordResponse returns the MongoDB update document recording the response of the request.
+func NewIdempotencyRecordResponse(resp *pb.CreateProjectResponse) (bson.M, error) {
+	response, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return util.WithUpdate(bson.M{"response": response}), nil
+}
+
+// InProgress returns whether the response of the request wasn't recorded yet.
+func (r *IdempotencyRecord) InProgress() bool {
+	return len(r.Response) == 0
+}
+
+// HashCreateProjectRequest returns a digest of the fields of a request, its request id excluded.
+func HashCreateProjectRequest(req *pb.CreateProjectRequest) (string, error) {
+	req = proto.Clone(req).(*pb.CreateProjectRequest)
+	req.RequestId = ""
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ToCreateProjectResponse returns the recorded response.
+func (r *IdempotencyRecord) ToCreateProjectResponse() (*pb.CreateProjectResponse, error) {
+	resp := new(pb.CreateProjectResponse)
+	if err := proto.Unmarshal(r.Response, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// Member gives a user a role on a project, there is at most one per user and project.
+type Member struct {
+	ID         string    `bson:"_id"` // See MemberID.
+	TenantID   string    `bson:"tenantId,omitempty"`
+	ProjectID  uuid.UUID `bson:"projectId"`
+	UserID     string    `bson:"userId"`
+	Role       string    `bson:"role"` // Name of ProjectMember.Role.
+	UserName   string    `bson:"-"`    // Resolved through the UserAPI, never stored.
+	CreateTime time.Time `bson:"createTime"`
+	UpdateTime time.Time `bson:"updateTime"`
+}
+
+// MemberID is the key of the membership of a user, so adding the same user twice is rejected by the repository.
+func MemberID(projectID uuid.UUID, userID string) string {
+	return projectID.String() + "/" + userID
+}
+
+func NewMember(projectID uuid.UUID, userID string, role pb.ProjectMember_Role) *Member {
+	now := time.Now().UTC()
+	return &Member{
+		ID:         MemberID(projectID, userID),
+		ProjectID:  projectID,
+		UserID:     userID,
+		Role:       role.String(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
+
+// NewMemberRoleUpdate returns the MongoDB update document changing the role of a member.
+func NewMemberRoleUpdate(role pb.ProjectMember_Role) bson.M {
+	return util.WithUpdate(bson.M{
+		"role":       role.String(),
+		"updateTime": time.Now().UTC(),
+	})
+}
+
+func (m *Member) ToAPI() *pb.ProjectMember {
+	return &pb.ProjectMember{
+		ProjectId:  m.ProjectID.String(),
+		UserId:     m.UserID,
+		Role:       pb.ProjectMember_Role(pb.ProjectMember_Role_value[m.Role]),
+		UserName:   m.UserName,
+		CreateTime: timestamppb.New(m.CreateTime),
+		UpdateTime: timestamppb.New(m.UpdateTime),
+	}
+}
+
+func (m *Member) ToAddMemberResponse() (*pb.AddMemberResponse, error) {
+	return &pb.AddMemberResponse{Member: m.ToAPI()}, nil
+}
+
+func (m *Member) ToUpdateMemberRoleResponse() (*pb.UpdateMemberRoleResponse, error) {
+	return &pb.UpdateMemberRoleResponse{Member: m.ToAPI()}, nil
+}
+
+func ToListMembersResponse(members []*Member) (*pb.ListMembersResponse, error) {
+	elements := make([]*pb.ProjectMember, 0, len(members))
+	for _, member := range members {
+		elements = append(elements, member.ToAPI())
+	}
+	return &pb.ListMembersResponse{Elements: elements}, nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+
+	"learning/grpc-project-service/pkg/util"
+)
+
+// pageToken content of the opaque page_token handed out by List calls.
+// It points at the last element of the previous page (keyset pagination) and remembers the query it belongs to,
+// so the token can't be reused with a different filter or ordering.
+type pageToken struct {
+	Query  string            `json:"q"`
+	Values map[string]string `json:"v"`
+}
+
+// queryFingerprint returns a short digest identifying the parts of a request that must stay the same between pages.
+func queryFingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:12])
+}
+
+func encodePageToken(key []byte, token *pageToken) (string, error) {
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return util.Sign(key, payload), nil
+}
+
+func decodePageToken(key []byte, query, token string) (*pageToken, error) {
+	payload, err := util.Verify(key, token)
+	if err != nil {
+		return nil, util.FieldViolation("page_token", "invalid page token")
+	}
+
+	decoded := new(pageToken)
+	if err := json.Unmarshal(payload, decoded); err != nil {
+		return nil, util.FieldViolation("page_token", "invalid page token")
+	}
+	if decoded.Query != query {
+		return nil, util.FieldViolation("page_token", "page token doesn't match the request, filter and order_by must not change between pages")
+	}
+	return decoded, nil
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+var testTokenKey = []byte("0123456789abcdef0123456789abcdef")
+
+// testProjects returns projects created an hour apart, two of them share a name.
+func testProjects() []*Project {
+	createTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	projects := make([]*Project, 0, 5)
+	for i, name := range []string{"a", "b", "b", "c", "d"} {
+		projects = append(projects, &Project{
+			ID:         uuid.Must(uuid.FromString(fmt.Sprintf("00000000-0000-4000-8000-00000000000%d", i))),
+			Name:       name,
+			CreateTime: createTime.Add(time.Duration(i) * time.Hour),
+			UpdateTime: createTime,
+		})
+	}
+	return projects
+}
+
+// listPage is the in-memory equivalent of a repository ListProjects call, it returns up to Limit+1 elements.
+func listPage(t *testing.T, req *pb.ListProjectsRequest, projects []*Project) *PageProjects {
+	t.Helper()
+	filter, err := NewListProjectFilter(req, testTokenKey)
+	if err != nil {
+		t.Fatalf("NewListProjectFilter() error = %v", err)
+	}
+
+	sorted := append([]*Project(nil), projects...)
+	sort.Slice(sorted, func(i, j int) bool { return filter.Compare(sorted[i], sorted[j]) < 0 })
+
+	result := make([]*Project, 0)
+	for _, p := range sorted {
+		if filter.Match(p) && filter.After(p) && int64(len(result)) <= filter.Limit {
+			result = append(result, p)
+		}
+	}
+	page, err := NewPageProjects(result, int64(len(projects)), filter)
+	if err != nil {
+		t.Fatalf("NewPageProjects() error = %v", err)
+	}
+	return page
+}
+
+func TestPageTokenWalksAllPages(t *testing.T) {
+	projects := testProjects()
+	req := &pb.ListProjectsRequest{Limit: 2, OrderBy: []string{"name, create_time desc"}}
+
+	var names []string
+	for pages := 0; ; pages++ {
+		if pages > len(projects) {
+			t.Fatal("pagination doesn't end")
+		}
+		page := listPage(t, req, projects)
+		for _, p := range page.Elements {
+			names = append(names, fmt.Sprintf("%s@%d", p.Name, p.CreateTime.Hour()))
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		req.PageToken = page.NextPageToken
+	}
+
+	want := []string{"a@0", "b@2", "b@1", "c@3", "d@4"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("pages returned %v, want %v", names, want)
+	}
+}
+
+func TestPageTokenRejected(t *testing.T) {
+	projects := testProjects()
+	first := listPage(t, &pb.ListProjectsRequest{Limit: 2, OrderBy: []string{"name"}, Filter: `name != "z"`}, projects)
+	if first.NextPageToken == "" {
+		t.Fatal("first page has no next_page_token")
+	}
+	token := first.NextPageToken
+
+	tests := []struct {
+		name string
+		req  *pb.ListProjectsRequest
+		key  []byte
+	}{
+		{name: "tampered", req: &pb.ListProjectsRequest{OrderBy: []string{"name"}, Filter: `name != "z"`, PageToken: "x" + token}},
+		{name: "garbage", req: &pb.ListProjectsRequest{OrderBy: []string{"name"}, Filter: `name != "z"`, PageToken: "not-a-token"}},
+		{name: "other key", req: &pb.ListProjectsRequest{OrderBy: []string{"name"}, Filter: `name != "z"`, PageToken: token}, key: []byte("another key")},
+		{name: "filter changed", req: &pb.ListProjectsRequest{OrderBy: []string{"name"}, Filter: `name != "y"`, PageToken: token}},
+		{name: "order_by changed", req: &pb.ListProjectsRequest{OrderBy: []string{"name desc"}, Filter: `name != "z"`, PageToken: token}},
+		{name: "show_deleted changed", req: &pb.ListProjectsRequest{OrderBy: []string{"name"}, Filter: `name != "z"`, ShowDeleted: true, PageToken: token}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := testTokenKey
+			if tt.key != nil {
+				key = tt.key
+			}
+			_, err := NewListProjectFilter(tt.req, key)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("NewListProjectFilter() error = %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package model
 
 type Pagination struct {
-	Limit  int64
-	Offset int64
-	Count  int64
+	Limit         int64
+	Offset        int64
+	Count         int64
+	NextPageToken string
 }
-
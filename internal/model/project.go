@@ -1,25 +1,71 @@
 package model
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	uuid "github.com/satori/go.uuid"
 	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/filtering"
+	"learning/grpc-project-service/pkg/util"
 )
 
+// labelKeyPattern matches the label keys allowed by the annotations of pb.Project,
+// for the labels.<key> update paths and the labels of filter expressions.
+var labelKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+
 type Project struct {
-	ID       uuid.UUID `bson:"_id"`
-	Name     string    `bson:"name"`
-	UserName string    `bson:"userName"`
+	ID          uuid.UUID         `bson:"_id"`
+	TenantID    string            `bson:"tenantId,omitempty"` // Set by the repository, from the tenant of the context.
+	Name        string            `bson:"name"`
+	Description string            `bson:"description"`
+	Labels      map[string]string `bson:"labels"`
+	OwnerID     string            `bson:"ownerId"`
+	OwnerName   string            `bson:"-"` // Resolved through the UserAPI, never stored.
+	CreateTime  time.Time         `bson:"createTime"`
+	UpdateTime  time.Time         `bson:"updateTime"`
+	ETag        string            `bson:"etag"`
+	DeleteTime  *time.Time        `bson:"deleteTime,omitempty"` // Set while the project is soft deleted.
+	ExpireTime  *time.Time        `bson:"expireTime,omitempty"` // When a soft deleted project gets purged.
+
+	GitHubRepositories []GitHubRepository `bson:"githubRepositories,omitempty"` // Linked through ReceiveGitHubEvent.
+	// Verifies the signatures of the GitHub webhooks of the project, never returned by the API once generated.
+	GitHubWebhookSecret string `bson:"githubWebhookSecret,omitempty"`
 }
 
 func NewProject(req *pb.CreateProjectRequest) (*Project, error) {
+	now := time.Now().UTC()
 	project := &Project{
-		ID:   uuid.NewV4(),
-		Name: req.GetName(),
+		ID:          uuid.NewV4(),
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Labels:      req.GetLabels(),
+		OwnerID:     req.GetOwnerId(),
+		CreateTime:  now,
+		UpdateTime:  now,
+		ETag:        NewETag(),
 	}
 	return project, nil
 }
 
+// NewETag returns a random opaque etag, a new one is stored on every write of a resource.
+func NewETag() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
 func (p *Project) ToCreateProjectResponse() (*pb.CreateProjectResponse, error) {
 	return &pb.CreateProjectResponse{Project: p.ToAPI()}, nil
 }
@@ -32,20 +78,291 @@ func (p *Project) ToUpdateProjectResponse() (*pb.UpdateProjectResponse, error) {
 	return &pb.UpdateProjectResponse{Project: p.ToAPI()}, nil
 }
 
+func (p *Project) ToDeleteProjectResponse() (*pb.DeleteProjectResponse, error) {
+	return &pb.DeleteProjectResponse{Project: p.ToAPI()}, nil
+}
+
+func (p *Project) ToUndeleteProjectResponse() (*pb.UndeleteProjectResponse, error) {
+	return &pb.UndeleteProjectResponse{Project: p.ToAPI()}, nil
+}
+
+// NewProjectDelete returns the MongoDB update document soft deleting a project, it expires after the retention period.
+func NewProjectDelete(retention time.Duration) bson.M {
+	now := time.Now().UTC()
+	return util.WithUpdate(bson.M{
+		"deleteTime": now,
+		"expireTime": now.Add(retention),
+		"updateTime": now,
+		"etag":       NewETag(),
+	})
+}
+
+// NewProjectUndelete returns the MongoDB update document restoring a soft deleted project.
+func NewProjectUndelete() bson.M {
+	return util.WithUpdate(bson.M{
+		"updateTime": time.Now().UTC(),
+		"etag":       NewETag(),
+	}, "deleteTime", "expireTime")
+}
+
 func (p *Project) ToAPI() *pb.Project {
-	return &pb.Project{
-		Id:   p.ID.String(),
-		Name: p.Name,
+	project := &pb.Project{
+		Id:          p.ID.String(),
+		Name:        p.Name,
+		Description: p.Description,
+		Labels:      p.Labels,
+		OwnerId:     p.OwnerID,
+		OwnerName:   p.OwnerName,
+		Etag:        p.ETag,
+	}
+	if !p.CreateTime.IsZero() {
+		project.CreateTime = timestamppb.New(p.CreateTime)
+	}
+	if !p.UpdateTime.IsZero() {
+		project.UpdateTime = timestamppb.New(p.UpdateTime)
+	}
+	if p.DeleteTime != nil {
+		project.DeleteTime = timestamppb.New(*p.DeleteTime)
+	}
+	if p.ExpireTime != nil {
+		project.ExpireTime = timestamppb.New(*p.ExpireTime)
+	}
+	for i := range p.GitHubRepositories {
+		project.GithubRepositories = append(project.GithubRepositories, p.GitHubRepositories[i].ToAPI())
+	}
+	return project
+}
+
+// projectUpdateKeys fields UpdateProject can change, indexed by field mask path.
+// Single labels can be changed with a "labels.<key>" path.
+var projectUpdateKeys = map[string]string{
+	"name":        "name",
+	"description": "description",
+	"labels":      "labels",
+}
+
+// projectOutputOnlyPaths fields set by the server, ignored when they show up in an update mask.
+var projectOutputOnlyPaths = map[string]struct{}{
+	"id":          {},
+	"create_time": {},
+	"update_time": {},
+	"owner_id":    {}, // Set on creation, the owner can't be changed.
+	"owner_name":  {},
+	"etag":        {},
+	"delete_time": {},
+	"expire_time": {},
+
+	"github_repositories": {},
+}
+
+// NewProjectUpdate returns the MongoDB update document for the paths of an AIP-134 update mask, nil when there is nothing to update.
+// Without a mask every populated updatable field is used, "*" replaces all updatable fields.
+func NewProjectUpdate(project *pb.Project, mask *fieldmaskpb.FieldMask) (bson.M, error) {
+	if project == nil {
+		project = new(pb.Project)
+	}
+
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		project.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if _, ok := projectUpdateKeys[string(fd.Name())]; ok {
+				paths = append(paths, string(fd.Name()))
+			}
+			return true
+		})
+	} else if len(paths) == 1 && paths[0] == "*" {
+		paths = make([]string, 0, len(projectUpdateKeys))
+		for path := range projectUpdateKeys {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+	}
+
+	set := bson.M{}
+	unset := make([]string, 0)
+	for _, path := range paths {
+		if _, ok := projectOutputOnlyPaths[path]; ok {
+			continue
+		}
+
+		if labelKey, ok := strings.CutPrefix(path, "labels."); ok {
+			if !labelKeyPattern.MatchString(labelKey) {
+				return nil, util.FieldViolation("update_mask", fmt.Sprintf("path %q is not a valid label", path))
+			}
+			if value, ok := project.Labels[labelKey]; ok {
+				set["labels."+labelKey] = value
+			} else {
+				unset = append(unset, "labels."+labelKey)
+			}
+			continue
+		}
+
+		key, ok := projectUpdateKeys[path]
+		switch {
+		case path == "*":
+			return nil, util.FieldViolation("update_mask", "\"*\" can't be combined with other paths")
+		case !ok:
+			return nil, util.FieldViolation("update_mask", fmt.Sprintf("path %q is not updatable", path))
+		}
+
+		switch path {
+		case "name":
+			if len(project.Name) == 0 {
+				return nil, util.FieldViolation("project.name", "name cannot be blank")
+			}
+			set[key] = project.Name
+		case "description":
+			set[key] = project.Description
+		case "labels":
+			set[key] = project.Labels
+		}
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		return nil, nil
+	}
+
+	set["updateTime"] = time.Now().UTC()
+	set["etag"] = NewETag()
+	return util.WithUpdate(set, unset...), nil
+}
+
+// projectSortKeys whitelist of the fields ListProjects can be ordered by.
+var projectSortKeys = map[string]string{
+	"id":          "_id",
+	"name":        "name",
+	"create_time": "createTime",
+	"update_time": "updateTime",
+}
+
+func compareProjectField(field string, a, b *Project) int {
+	switch field {
+	case "id":
+		return bytes.Compare(a.ID.Bytes(), b.ID.Bytes())
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "create_time":
+		return a.CreateTime.Compare(b.CreateTime)
+	case "update_time":
+		return a.UpdateTime.Compare(b.UpdateTime)
+	}
+	return 0
+}
+
+// projectFieldValue returns the stored value of a sortable field.
+func projectFieldValue(field string, p *Project) interface{} {
+	switch field {
+	case "id":
+		return p.ID
+	case "name":
+		return p.Name
+	case "create_time":
+		return p.CreateTime
+	case "update_time":
+		return p.UpdateTime
+	}
+	return nil
+}
+
+func formatProjectField(field string, p *Project) string {
+	switch field {
+	case "id":
+		return p.ID.String()
+	case "name":
+		return p.Name
+	case "create_time":
+		return p.CreateTime.Format(time.RFC3339Nano)
+	case "update_time":
+		return p.UpdateTime.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+func parseProjectField(field string, p *Project, value string) error {
+	switch field {
+	case "id":
+		id, err := uuid.FromString(value)
+		if err != nil {
+			return err
+		}
+		p.ID = id
+	case "name":
+		p.Name = value
+	case "create_time", "update_time":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		if field == "create_time" {
+			p.CreateTime = t
+		} else {
+			p.UpdateTime = t
+		}
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+	return nil
+}
+
+// projectFilterSchema fields usable in ListProjects filter expressions.
+var projectFilterSchema = filtering.Schema{
+	"id":          {Key: "_id", Type: filtering.TypeUUID},
+	"name":        {Key: "name", Type: filtering.TypeString},
+	"description": {Key: "description", Type: filtering.TypeString},
+	"labels":      {Key: "labels", Type: filtering.TypeMap, KeyPattern: labelKeyPattern},
+	"owner_id":    {Key: "ownerId", Type: filtering.TypeString},
+	"create_time": {Key: "createTime", Type: filtering.TypeTimestamp},
+	"update_time": {Key: "updateTime", Type: filtering.TypeTimestamp},
+	"delete_time": {Key: "deleteTime", Type: filtering.TypeTimestamp},
+	"expire_time": {Key: "expireTime", Type: filtering.TypeTimestamp},
+}
+
+// projectFilterValue is the filtering.Getter of a Project.
+func projectFilterValue(p *Project) filtering.Getter {
+	return func(field, key string) (interface{}, bool) {
+		switch field {
+		case "id":
+			return p.ID.String(), true
+		case "name":
+			return p.Name, true
+		case "description":
+			return p.Description, true
+		case "labels":
+			value, ok := p.Labels[key]
+			return value, ok
+		case "owner_id":
+			return p.OwnerID, true
+		case "create_time":
+			return p.CreateTime, true
+		case "update_time":
+			return p.UpdateTime, true
+		case "delete_time":
+			if p.DeleteTime != nil {
+				return *p.DeleteTime, true
+			}
+		case "expire_time":
+			if p.ExpireTime != nil {
+				return *p.ExpireTime, true
+			}
+		}
+		return nil, false
 	}
 }
 
 type ListProjectsFilter struct {
 	Filter
-	Name    string
-	OrderBy []string
+	Name        string
+	OrderBy     []SortField
+	Expression  *filtering.Filter // Parsed AIP-160 filter of the request.
+	ShowDeleted bool              // Include soft deleted projects.
+
+	tokenKey []byte
+	query    string
+	after    *Project // Last element of the previous page, nil when paginating with offset.
 }
 
-func NewListProjectFilter(req *pb.ListProjectsRequest) *ListProjectsFilter {
+// NewListProjectFilter creates the filter for a ListProjects request. Page tokens are signed and verified with tokenKey.
+func NewListProjectFilter(req *pb.ListProjectsRequest, tokenKey []byte) (*ListProjectsFilter, error) {
 	l := &ListProjectsFilter{Filter: DefaultFilter()}
 
 	if req.Offset != 0 {
@@ -56,9 +373,39 @@ func NewListProjectFilter(req *pb.ListProjectsRequest) *ListProjectsFilter {
 		l.Limit = req.Limit
 	}
 
+	orderBy, err := ParseOrderBy(req.OrderBy, projectSortKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	expression, err := filtering.Parse(req.Filter, projectFilterSchema)
+	if err != nil {
+		return nil, util.FieldViolation("filter", err.Error())
+	}
+
 	l.Name = req.Name
-	l.OrderBy = req.OrderBy
-	return l
+	l.OrderBy = orderBy
+	l.Expression = expression
+	l.ShowDeleted = req.ShowDeleted
+	l.tokenKey = tokenKey
+	l.query = queryFingerprint(l.Name, req.Filter, orderByFingerprint(l.OrderBy), strconv.FormatBool(l.ShowDeleted))
+
+	if len(req.PageToken) > 0 {
+		token, err := decodePageToken(tokenKey, l.query, req.PageToken)
+		if err != nil {
+			return nil, err
+		}
+
+		after := new(Project)
+		for _, f := range withTiebreaker(l.OrderBy) {
+			if err := parseProjectField(f.Field, after, token.Values[f.Field]); err != nil {
+				return nil, util.FieldViolation("page_token", "invalid page token")
+			}
+		}
+		l.after = after
+		l.Offset = 0
+	}
+	return l, nil
 }
 
 func (l *ListProjectsFilter) GetFilter() bson.M {
@@ -67,15 +414,70 @@ func (l *ListProjectsFilter) GetFilter() bson.M {
 	if len(l.Name) > 0 {
 		ret["name"] = l.Name
 	}
+
+	if !l.ShowDeleted {
+		ret["deleteTime"] = nil
+	}
+
+	if expression := l.Expression.BSON(); len(expression) > 0 {
+		return bson.M{"$and": bson.A{ret, expression}}
+	}
 	return ret
 }
 
-func (l *ListProjectsFilter) GetSort() bson.M {
-	var ret = bson.M{}
-	if len(l.OrderBy) > 0 {
+// GetPageFilter GetFilter restricted to the elements following the page token, if any.
+func (l *ListProjectsFilter) GetPageFilter() bson.M {
+	if l.after == nil {
+		return l.GetFilter()
+	}
 
+	anchor := make(map[string]interface{})
+	for _, f := range withTiebreaker(l.OrderBy) {
+		anchor[f.Key] = projectFieldValue(f.Field, l.after)
 	}
-	return ret
+	return bson.M{"$and": bson.A{l.GetFilter(), KeysetDocument(l.OrderBy, anchor)}}
+}
+
+// After is the in-memory equivalent of the keyset part of GetPageFilter.
+func (l *ListProjectsFilter) After(p *Project) bool {
+	return l.after == nil || l.Compare(p, l.after) > 0
+}
+
+// Match is the in-memory equivalent of GetFilter.
+func (l *ListProjectsFilter) Match(p *Project) bool {
+	if len(l.Name) > 0 && p.Name != l.Name {
+		return false
+	}
+	if !l.ShowDeleted && p.DeleteTime != nil {
+		return false
+	}
+	return l.Expression.Match(projectFilterValue(p))
+}
+
+func (l *ListProjectsFilter) GetSort() bson.D {
+	return SortDocument(l.OrderBy)
+}
+
+// Compare is the in-memory equivalent of GetSort, including the trailing id tiebreaker.
+func (l *ListProjectsFilter) Compare(a, b *Project) int {
+	for _, f := range withTiebreaker(l.OrderBy) {
+		c := compareProjectField(f.Field, a, b)
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (l *ListProjectsFilter) nextPageToken(last *Project) (string, error) {
+	token := &pageToken{Query: l.query, Values: make(map[string]string)}
+	for _, f := range withTiebreaker(l.OrderBy) {
+		token.Values[f.Field] = formatProjectField(f.Field, last)
+	}
+	return encodePageToken(l.tokenKey, token)
 }
 
 type PageProjects struct {
@@ -83,23 +485,36 @@ type PageProjects struct {
 	Elements []*Project
 }
 
-func NewPageProjects(projects []*Project, count, limit, offset int64) *PageProjects {
-	return &PageProjects{
+// NewPageProjects creates a page out of the repository result.
+// Repositories return up to Limit+1 elements, the extra one only tells another page follows and is left out.
+func NewPageProjects(projects []*Project, count int64, filter *ListProjectsFilter) (*PageProjects, error) {
+	page := &PageProjects{
 		Pagination: Pagination{
-			Limit:  limit,
-			Offset: offset,
+			Limit:  filter.Limit,
+			Offset: filter.Offset,
 			Count:  count,
 		},
 		Elements: projects,
 	}
+
+	if int64(len(projects)) > filter.Limit {
+		page.Elements = projects[:filter.Limit]
+		token, err := filter.nextPageToken(page.Elements[len(page.Elements)-1])
+		if err != nil {
+			return nil, err
+		}
+		page.NextPageToken = token
+	}
+	return page, nil
 }
 
 func (p *PageProjects) ToListProjectsAPI() (*pb.ListProjectsResponse, error) {
 	projects := &pb.ListProjectsResponse{
-		Count:    p.Count,
-		Offset:   p.Offset,
-		Limit:    p.Limit,
-		Elements: make([]*pb.Project, 0),
+		Count:         p.Count,
+		Offset:        p.Offset,
+		Limit:         p.Limit,
+		Elements:      make([]*pb.Project, 0),
+		NextPageToken: p.NextPageToken,
 	}
 	for _, e := range p.Elements {
 		projects.Elements = append(projects.Elements, e.ToAPI())
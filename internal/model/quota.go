@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+// Names of the quotas, as returned by the QuotaAPI.
+const (
+	QuotaProjectsPerOwner  = "projects_per_owner"
+	QuotaProjectsPerTenant = "projects_per_tenant"
+)
+
+// QuotaLimits maximum number of projects, 0 means unlimited.
+// Nil fields of a tenant override keep the default limit.
+type QuotaLimits struct {
+	ProjectsPerOwner  *int64 `yaml:"projects_per_owner"`
+	ProjectsPerTenant *int64 `yaml:"projects_per_tenant"`
+}
+
+// QuotaOverrides replaces the default limits of some tenants, eg:
+//
+//	tenants:
+//	  acme:
+//	    projects_per_owner: 50
+//	    projects_per_tenant: 1000
+type QuotaOverrides struct {
+	Tenants map[string]*QuotaLimits `yaml:"tenants"`
+}
+
+// Quota is a limit applied to a subject, eg: the projects of an owner, along with its current usage.
+type Quota struct {
+	Name    string
+	Subject string
+	Limit   int64
+	Usage   int64
+}
+
+// QuotaUsage counts the projects against a quota. It is updated along with the projects, conditionally when it grows,
+// so concurrent requests can't exceed the limit together.
+type QuotaUsage struct {
+	ID       string `bson:"_id"` // See QuotaUsageID.
+	TenantID string `bson:"tenantId,omitempty"`
+	Usage    int64  `bson:"usage"`
+}
+
+// QuotaUsageID returns the id of the usage of a quota, subjects of distinct tenants don't collide.
+func QuotaUsageID(tenantID, name, subject string) string {
+	return tenantID + "/" + name + "/" + subject
+}
+
+func NewQuotaUsage(id string, usage int64) *QuotaUsage {
+	return &QuotaUsage{ID: id, Usage: usage}
+}
+
+func OwnerQuotaSubject(ownerID string) string {
+	return "owners/" + ownerID
+}
+
+func TenantQuotaSubject(tenantID string) string {
+	return "tenants/" + tenantID
+}
+
+func (q *Quota) Description() string {
+	return fmt.Sprintf("quota %s of %s exhausted, at most %d projects are allowed", q.Name, q.Subject, q.Limit)
+}
+
+func (q *Quota) ToAPI() *pb.Quota {
+	return &pb.Quota{
+		Name:    q.Name,
+		Subject: q.Subject,
+		Limit:   q.Limit,
+		Usage:   q.Usage,
+	}
+}
+
+func ToGetQuotaResponse(quotas []*Quota) (*pb.GetQuotaResponse, error) {
+	resp := &pb.GetQuotaResponse{Quotas: make([]*pb.Quota, 0, len(quotas))}
+	for _, quota := range quotas {
+		resp.Quotas = append(resp.Quotas, quota.ToAPI())
+	}
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// SortField a single ordering key of an AIP-132 order_by clause.
+type SortField struct {
+	Field string // API field name, eg: "create_time".
+	Key   string // Document key the field is stored under, eg: "createTime".
+	Desc  bool
+}
+
+// ParseOrderBy parses AIP-132 ordering clauses like "name desc, create_time".
+// Only fields present in sortable (API field name to document key) are accepted.
+func ParseOrderBy(orderBy []string, sortable map[string]string) ([]SortField, error) {
+	fields := make([]SortField, 0)
+	seen := make(map[string]struct{})
+
+	for _, clause := range orderBy {
+		for _, item := range strings.Split(clause, ",") {
+			parts := strings.Fields(item)
+			if len(parts) == 0 {
+				continue
+			}
+
+			field := SortField{Field: parts[0]}
+			if len(parts) > 2 {
+				return nil, util.FieldViolation("order_by", fmt.Sprintf("invalid ordering %q", strings.TrimSpace(item)))
+			}
+			if len(parts) == 2 {
+				switch strings.ToLower(parts[1]) {
+				case "asc":
+				case "desc":
+					field.Desc = true
+				default:
+					return nil, util.FieldViolation("order_by", fmt.Sprintf("invalid direction %q for field %q", parts[1], field.Field))
+				}
+			}
+
+			key, ok := sortable[field.Field]
+			if !ok {
+				return nil, util.FieldViolation("order_by", fmt.Sprintf("field %q is not sortable", field.Field))
+			}
+			if _, ok := seen[field.Field]; ok {
+				return nil, util.FieldViolation("order_by", fmt.Sprintf("field %q is used more than once", field.Field))
+			}
+			seen[field.Field] = struct{}{}
+
+			field.Key = key
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// SortDocument converts the ordering into a MongoDB sort document.
+// The _id is always appended as the last key, so documents with equal values keep a stable order across pages.
+func SortDocument(fields []SortField) bson.D {
+	sort := bson.D{}
+	for _, f := range withTiebreaker(fields) {
+		direction := 1
+		if f.Desc {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: f.Key, Value: direction})
+	}
+	return sort
+}
+
+// KeysetDocument returns a MongoDB filter selecting the documents that come after the anchor in the given ordering.
+// The anchor holds the values of the last seen document, indexed by document key.
+func KeysetDocument(fields []SortField, anchor map[string]interface{}) bson.M {
+	fields = withTiebreaker(fields)
+
+	or := bson.A{}
+	for i, f := range fields {
+		cond := bson.M{}
+		for _, prev := range fields[:i] {
+			cond[prev.Key] = anchor[prev.Key]
+		}
+		op := "$gt"
+		if f.Desc {
+			op = "$lt"
+		}
+		cond[f.Key] = bson.M{op: anchor[f.Key]}
+		or = append(or, cond)
+	}
+	return bson.M{"$or": or}
+}
+
+// orderByFingerprint canonical representation of the ordering, used to bind page tokens to it.
+func orderByFingerprint(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f.Desc {
+			parts = append(parts, f.Field+" desc")
+		} else {
+			parts = append(parts, f.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func withTiebreaker(fields []SortField) []SortField {
+	for i, f := range fields {
+		if f.Key == "_id" {
+			return fields[:i+1]
+		}
+	}
+	return append(append([]SortField{}, fields...), SortField{Field: "id", Key: "_id"})
+}
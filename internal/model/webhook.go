@@ -0,0 +1,246 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/util"
+)
+
+type Webhook struct {
+	ID         uuid.UUID `bson:"_id"`
+	TenantID   string    `bson:"tenantId,omitempty"`
+	URL        string    `bson:"url"`
+	EventTypes []string  `bson:"eventTypes"` // Names of WatchProjectsResponse.EventType, all events when empty.
+	Secret     string    `bson:"secret"`
+	CreateTime time.Time `bson:"createTime"`
+}
+
+func NewWebhook(req *pb.CreateWebhookRequest) *Webhook {
+	eventTypes := make([]string, 0, len(req.GetEventTypes()))
+	for _, eventType := range req.GetEventTypes() {
+		eventTypes = append(eventTypes, eventType.String())
+	}
+
+	return &Webhook{
+		ID:         uuid.NewV4(),
+		URL:        req.GetUrl(),
+		EventTypes: eventTypes,
+		Secret:     req.GetSecret(),
+		CreateTime: time.Now().UTC(),
+	}
+}
+
+// Subscribed returns whether the webhook receives events of the given type.
+func (w *Webhook) Subscribed(eventType pb.WatchProjectsResponse_EventType) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// ToAPI never returns the secret.
+func (w *Webhook) ToAPI() *pb.Webhook {
+	eventTypes := make([]pb.WatchProjectsResponse_EventType, 0, len(w.EventTypes))
+	for _, t := range w.EventTypes {
+		eventTypes = append(eventTypes, pb.WatchProjectsResponse_EventType(pb.WatchProjectsResponse_EventType_value[t]))
+	}
+
+	return &pb.Webhook{
+		Id:         w.ID.String(),
+		Url:        w.URL,
+		EventTypes: eventTypes,
+		CreateTime: timestamppb.New(w.CreateTime),
+	}
+}
+
+func (w *Webhook) ToCreateWebhookResponse() (*pb.CreateWebhookResponse, error) {
+	return &pb.CreateWebhookResponse{Webhook: w.ToAPI()}, nil
+}
+
+func (w *Webhook) ToGetWebhookResponse() (*pb.GetWebhookResponse, error) {
+	return &pb.GetWebhookResponse{Webhook: w.ToAPI()}, nil
+}
+
+func ToListWebhooksResponse(webhooks []*Webhook) (*pb.ListWebhooksResponse, error) {
+	elements := make([]*pb.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		elements = append(elements, webhook.ToAPI())
+	}
+	return &pb.ListWebhooksResponse{Elements: elements}, nil
+}
+
+// CloudEvent is the structured mode JSON format of CloudEvents 1.0, see https://github.com/cloudevents/spec.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewProjectCloudEvent returns the CloudEvent of a project change, its type is eg: platform.v1.project.created.
+func NewProjectCloudEvent(source string, eventType pb.WatchProjectsResponse_EventType, project *pb.Project, eventTime time.Time) (*CloudEvent, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(project)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              uuid.NewV4().String(),
+		Source:          source,
+		Type:            "platform.v1.project." + strings.ToLower(eventType.String()),
+		Subject:         "projects/" + project.GetId(),
+		Time:            eventTime,
+		DataContentType: "application/json",
+		Data:            data,
+	}, nil
+}
+
+type Delivery struct {
+	ID              uuid.UUID  `bson:"_id"`
+	TenantID        string     `bson:"tenantId,omitempty"`
+	WebhookID       uuid.UUID  `bson:"webhookId"`
+	EventID         string     `bson:"eventId"`
+	EventType       string     `bson:"eventType"`
+	Payload         string     `bson:"payload"`
+	State           string     `bson:"state"` // Name of Delivery.State.
+	Attempts        int32      `bson:"attempts"`
+	LastStatusCode  int32      `bson:"lastStatusCode"`
+	LastError       string     `bson:"lastError"`
+	CreateTime      time.Time  `bson:"createTime"`
+	UpdateTime      time.Time  `bson:"updateTime"`
+	NextAttemptTime *time.Time `bson:"nextAttemptTime,omitempty"` // Only set while the delivery is pending.
+}
+
+// NewDelivery returns a pending delivery of the event, attempted right away.
+func NewDelivery(webhook *Webhook, event *CloudEvent) (*Delivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Delivery{
+		ID:              uuid.NewV4(),
+		TenantID:        webhook.TenantID,
+		WebhookID:       webhook.ID,
+		EventID:         event.ID,
+		EventType:       event.Type,
+		Payload:         string(payload),
+		State:           pb.Delivery_PENDING.String(),
+		CreateTime:      now,
+		UpdateTime:      now,
+		NextAttemptTime: &now,
+	}, nil
+}
+
+// NewAttempt returns the MongoDB update document recording an attempt, attemptErr is nil when it succeeded.
+// A failed delivery is retried at nextAttempt, or moved to the dead letters when nextAttempt is nil.
+func (d *Delivery) NewAttempt(statusCode int, attemptErr error, nextAttempt *time.Time) bson.M {
+	set := bson.M{
+		"state":          pb.Delivery_SUCCEEDED.String(),
+		"attempts":       d.Attempts + 1,
+		"lastStatusCode": int32(statusCode),
+		"lastError":      "",
+		"updateTime":     time.Now().UTC(),
+	}
+	if attemptErr != nil {
+		set["lastError"] = attemptErr.Error()
+		set["state"] = pb.Delivery_DEAD_LETTER.String()
+		if nextAttempt != nil {
+			set["state"] = pb.Delivery_PENDING.String()
+			set["nextAttemptTime"] = *nextAttempt
+			return util.WithUpdate(set)
+		}
+	}
+	return util.WithUpdate(set, "nextAttemptTime")
+}
+
+func (d *Delivery) ToAPI() *pb.Delivery {
+	delivery := &pb.Delivery{
+		Id:             d.ID.String(),
+		WebhookId:      d.WebhookID.String(),
+		EventId:        d.EventID,
+		EventType:      d.EventType,
+		State:          pb.Delivery_State(pb.Delivery_State_value[d.State]),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreateTime:     timestamppb.New(d.CreateTime),
+		UpdateTime:     timestamppb.New(d.UpdateTime),
+		Payload:        d.Payload,
+	}
+	if d.NextAttemptTime != nil {
+		delivery.NextAttemptTime = timestamppb.New(*d.NextAttemptTime)
+	}
+	return delivery
+}
+
+// ListDeliveriesFilter selects the deliveries returned by ListDeliveries.
+type ListDeliveriesFilter struct {
+	WebhookID *uuid.UUID // All webhooks when nil.
+	State     string     // All states when empty.
+	Offset    int64
+	Limit     int64
+}
+
+const defaultDeliveriesLimit = 20
+
+func NewListDeliveriesFilter(req *pb.ListDeliveriesRequest) *ListDeliveriesFilter {
+	filter := &ListDeliveriesFilter{
+		Offset: req.GetOffset(),
+		Limit:  req.GetLimit(),
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultDeliveriesLimit
+	}
+	if req.GetWebhookId() != "-" {
+		id := uuid.FromStringOrNil(req.GetWebhookId())
+		filter.WebhookID = &id
+	}
+	if req.GetState() != pb.Delivery_STATE_UNSPECIFIED {
+		filter.State = req.GetState().String()
+	}
+	return filter
+}
+
+// GetFilter returns the MongoDB filter of the deliveries.
+func (f *ListDeliveriesFilter) GetFilter() bson.M {
+	filter := bson.M{}
+	if f.WebhookID != nil {
+		filter["webhookId"] = *f.WebhookID
+	}
+	if len(f.State) > 0 {
+		filter["state"] = f.State
+	}
+	return filter
+}
+
+func ToListDeliveriesResponse(deliveries []*Delivery, count int64, filter *ListDeliveriesFilter) (*pb.ListDeliveriesResponse, error) {
+	elements := make([]*pb.Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		elements = append(elements, delivery.ToAPI())
+	}
+	return &pb.ListDeliveriesResponse{
+		Count:    count,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+		Elements: elements,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+const collectionApiKey = "apiKey"
+
+type ApiKeyRepository interface {
+	CreateApiKey(context.Context, *model.ApiKey) error
+	GetApiKey(context.Context, bson.M) (*model.ApiKey, error)
+	// FindApiKeys returns all keys matching the filter, oldest first.
+	FindApiKeys(context.Context, bson.M) ([]*model.ApiKey, error)
+	UpdateApiKey(ctx context.Context, filter bson.M, update bson.M) error
+}
+
+func (r *repository) CreateApiKey(ctx context.Context, key *model.ApiKey) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateApiKey")
+	defer span.Finish()
+
+	stampTenant(ctx, &key.TenantID)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionApiKey).InsertOne(ctx, *key)
+	if mongo.IsDuplicateKeyError(err) {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	return err
+}
+
+func (r *repository) GetApiKey(ctx context.Context, filter bson.M) (key *model.ApiKey, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetApiKey")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	err = r.MongoDatabase(ctx).Collection(collectionApiKey).FindOne(ctx, filter).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return
+}
+
+func (r *repository) FindApiKeys(ctx context.Context, filter bson.M) ([]*model.ApiKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindApiKeys")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createTime", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.MongoDatabase(ctx).Collection(collectionApiKey).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*model.ApiKey, 0)
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *repository) UpdateApiKey(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateApiKey")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionApiKey).UpdateOne(ctx, filter, update)
+	if result != nil && result.MatchedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	BackendMemory  = "memory"
+	BackendMongoDB = "mongodb"
+)
+
+// Config selects the storage backend of the Repository.
+type Config struct {
+	Backend string // Either BackendMemory or BackendMongoDB.
+}
+
+// NewConfigFromEnv initializes the configuration from environment variables or config file.
+func NewConfigFromEnv() *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	v.SetDefault("REPOSITORY_BACKEND", BackendMemory)
+
+	config.LoadFromFile(v)
+
+	backend := v.GetString("REPOSITORY_BACKEND")
+
+	logrus.WithField("backend", backend).Debug("Repository Config Initialized")
+
+	return &Config{
+		Backend: backend,
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+const collectionGitHubDelivery = "githubDelivery"
+
+type GitHubDeliveryRepository interface {
+	// CreateGitHubDelivery fails with AlreadyExists when the delivery was already received.
+	CreateGitHubDelivery(context.Context, *model.GitHubDelivery) error
+	DeleteGitHubDelivery(ctx context.Context, filter bson.M) error
+	// PurgeGitHubDeliveries removes the deliveries received before the given time.
+	PurgeGitHubDeliveries(ctx context.Context, receivedBefore time.Time) (int64, error)
+}
+
+func (r *repository) CreateGitHubDelivery(ctx context.Context, delivery *model.GitHubDelivery) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateGitHubDelivery")
+	defer span.Finish()
+
+	stampTenant(ctx, &delivery.TenantID)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionGitHubDelivery).InsertOne(ctx, *delivery)
+	if mongo.IsDuplicateKeyError(err) {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	return err
+}
+
+func (r *repository) DeleteGitHubDelivery(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteGitHubDelivery")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionGitHubDelivery).DeleteOne(ctx, filter)
+	if result != nil && result.DeletedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) PurgeGitHubDeliveries(ctx context.Context, receivedBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeGitHubDeliveries")
+	defer span.Finish()
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionGitHubDelivery).DeleteMany(ctx, scoped(ctx, bson.M{"receiveTime": bson.M{"$lt": receivedBefore}}))
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+const collectionIdempotencyRecord = "idempotencyRecord"
+
+type IdempotencyRecordRepository interface {
+	// CreateIdempotencyRecord fails with AlreadyExists when a record with the same id exists.
+	CreateIdempotencyRecord(context.Context, *model.IdempotencyRecord) error
+	GetIdempotencyRecord(ctx context.Context, filter bson.M) (*model.IdempotencyRecord, error)
+	UpdateIdempotencyRecord(ctx context.Context, filter bson.M, update bson.M) error
+	DeleteIdempotencyRecord(ctx context.Context, filter bson.M) error
+	// PurgeIdempotencyRecords removes the records created before the given time.
+	PurgeIdempotencyRecords(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+func (r *repository) CreateIdempotencyRecord(ctx context.Context, record *model.IdempotencyRecord) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateIdempotencyRecord")
+	defer span.Finish()
+
+	stampTenant(ctx, &record.TenantID)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionIdempotencyRecord).InsertOne(ctx, *record)
+	if mongo.IsDuplicateKeyError(err) {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	return err
+}
+
+func (r *repository) GetIdempotencyRecord(ctx context.Context, filter bson.M) (record *model.IdempotencyRecord, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetIdempotencyRecord")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	err = r.MongoDatabase(ctx).Collection(collectionIdempotencyRecord).FindOne(ctx, filter).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return
+}
+
+func (r *repository) UpdateIdempotencyRecord(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateIdempotencyRecord")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionIdempotencyRecord).UpdateOne(ctx, filter, update)
+	if result != nil && result.MatchedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) DeleteIdempotencyRecord(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteIdempotencyRecord")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionIdempotencyRecord).DeleteOne(ctx, filter)
+	if result != nil && result.DeletedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) PurgeIdempotencyRecords(ctx context.Context, createdBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeIdempotencyRecords")
+	defer span.Finish()
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionIdempotencyRecord).DeleteMany(ctx, scoped(ctx, bson.M{"createTime": bson.M{"$lt": createdBefore}}))
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
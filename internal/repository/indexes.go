@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexes of every collection. Queries are scoped to a tenant, see scoped, so most indexes start with tenantId.
+// Purges and the Dispatcher run across tenants, their indexes don't.
+var indexes = map[string][]mongo.IndexModel{
+	collectionProject: {
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createTime", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "ownerId", Value: 1}}},
+		{Keys: bson.D{{Key: "expireTime", Value: 1}}, Options: options.Index().SetSparse(true)},
+	},
+	collectionMember: {
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "projectId", Value: 1}}},
+		{Keys: bson.D{{Key: "projectId", Value: 1}}},
+	},
+	collectionApiKey: {
+		// Keys are looked up by prefix before the tenant of the caller is known.
+		{Keys: bson.D{{Key: "prefix", Value: 1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createTime", Value: 1}}},
+	},
+	collectionWebhook: {
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createTime", Value: 1}}},
+	},
+	collectionDelivery: {
+		{Keys: bson.D{{Key: "state", Value: 1}, {Key: "nextAttemptTime", Value: 1}}},
+		{Keys: bson.D{{Key: "state", Value: 1}, {Key: "updateTime", Value: 1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "webhookId", Value: 1}, {Key: "createTime", Value: -1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createTime", Value: -1}}},
+	},
+	collectionGitHubDelivery: {
+		{Keys: bson.D{{Key: "receiveTime", Value: 1}}},
+	},
+	collectionIdempotencyRecord: {
+		{Keys: bson.D{{Key: "createTime", Value: 1}}},
+	},
+}
+
+// createIndexes creates the missing indexes, existing ones are left untouched.
+func (r *repository) createIndexes(ctx context.Context) error {
+	for collection, models := range indexes {
+		if _, err := r.MongoDatabase(ctx).Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+const collectionMember = "member"
+
+type MemberRepository interface {
+	CreateMember(context.Context, *model.Member) error
+	GetMember(context.Context, bson.M) (*model.Member, error)
+	// FindMembers returns all members matching the filter, in the order they were added.
+	FindMembers(context.Context, bson.M) ([]*model.Member, error)
+	UpdateMember(ctx context.Context, filter bson.M, update bson.M) error
+	DeleteMember(ctx context.Context, filter bson.M) error
+}
+
+func (r *repository) CreateMember(ctx context.Context, member *model.Member) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateMember")
+	defer span.Finish()
+
+	stampTenant(ctx, &member.TenantID)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionMember).InsertOne(ctx, *member)
+	if mongo.IsDuplicateKeyError(err) {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	return err
+}
+
+func (r *repository) GetMember(ctx context.Context, filter bson.M) (member *model.Member, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetMember")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	err = r.MongoDatabase(ctx).Collection(collectionMember).FindOne(ctx, filter).Decode(&member)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return
+}
+
+func (r *repository) FindMembers(ctx context.Context, filter bson.M) ([]*model.Member, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindMembers")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createTime", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.MongoDatabase(ctx).Collection(collectionMember).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*model.Member, 0)
+	if err = cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *repository) UpdateMember(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateMember")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionMember).UpdateOne(ctx, filter, update)
+	if result != nil && result.MatchedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) DeleteMember(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteMember")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionMember).DeleteOne(ctx, filter)
+	if result != nil && result.DeletedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memoryRepository keeps all data in process memory.
+// Documents are stored in their BSON form, so the filters and updates built by the service layer
+// (see pkg/util) behave the same way they do against MongoDB.
+type memoryRepository struct {
+	mu                 sync.RWMutex
+	projects           *collection
+	webhooks           *collection
+	deliveries         *collection
+	githubDeliveries   *collection
+	members            *collection
+	apiKeys            *collection
+	idempotencyRecords *collection
+	quotaUsages        *collection
+}
+
+// NewMemory creates a Repository that doesn't need any external service.
+// Mostly usable for local development and integration tests, all data is lost on restart.
+func NewMemory() Repository {
+	return &memoryRepository{
+		projects:           newCollection(),
+		webhooks:           newCollection(),
+		deliveries:         newCollection(),
+		githubDeliveries:   newCollection(),
+		members:            newCollection(),
+		apiKeys:            newCollection(),
+		idempotencyRecords: newCollection(),
+		quotaUsages:        newCollection(),
+	}
+}
+
+// memoryTransactionKey marks the context of a RunInTransaction callback, its value is the repository holding the lock.
+type memoryTransactionKey struct{}
+
+// RunInTransaction holds the write lock while fn runs, the collections are restored when it fails.
+func (r *memoryRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memoryTransactionKey{}) == r {
+		return fn(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	collections := []**collection{&r.projects, &r.webhooks, &r.deliveries, &r.githubDeliveries, &r.members, &r.apiKeys, &r.idempotencyRecords, &r.quotaUsages}
+	snapshots := make([]*collection, len(collections))
+	for i, c := range collections {
+		snapshots[i] = (*c).snapshot()
+	}
+	if err := fn(context.WithValue(ctx, memoryTransactionKey{}, r)); err != nil {
+		for i, c := range collections {
+			*c = snapshots[i]
+		}
+		return err
+	}
+	return nil
+}
+
+// lock takes the write lock, unless ctx belongs to a transaction which already holds it. Returns the unlock function.
+func (r *memoryRepository) lock(ctx context.Context) func() {
+	if ctx.Value(memoryTransactionKey{}) == r {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// rlock is the read lock counterpart of lock.
+func (r *memoryRepository) rlock(ctx context.Context) func() {
+	if ctx.Value(memoryTransactionKey{}) == r {
+		return func() {}
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+// collection is an insertion ordered set of BSON documents indexed by their _id.
+// It isn't safe for concurrent use, callers are expected to hold the memoryRepository lock.
+type collection struct {
+	keys []string
+	docs map[string]bson.M
+}
+
+func newCollection() *collection {
+	return &collection{docs: make(map[string]bson.M)}
+}
+
+// snapshot returns a copy of the collection. Stored documents are never modified in place, so they can be shared.
+func (c *collection) snapshot() *collection {
+	docs := make(map[string]bson.M, len(c.docs))
+	for key, doc := range c.docs {
+		docs[key] = doc
+	}
+	return &collection{keys: append([]string(nil), c.keys...), docs: docs}
+}
+
+func (c *collection) insert(document interface{}) error {
+	doc, err := toDocument(document)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprint(doc["_id"])
+	if _, ok := c.docs[key]; ok {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	c.keys = append(c.keys, key)
+	c.docs[key] = doc
+	return nil
+}
+
+// find returns the keys of all documents matching the filter, in insertion order.
+func (c *collection) find(filter bson.M) ([]string, error) {
+	f, err := toDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	for _, key := range c.keys {
+		ok, err := matchDocument(c.docs[key], f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (c *collection) decode(key string, out interface{}) error {
+	raw, err := bson.Marshal(c.docs[key])
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, out)
+}
+
+func (c *collection) update(key string, update bson.M) error {
+	u, err := toDocument(update)
+	if err != nil {
+		return err
+	}
+
+	// Work on a copy so a failing update doesn't leave a half applied document behind.
+	doc, err := toDocument(c.docs[key])
+	if err != nil {
+		return err
+	}
+	for op, fields := range u {
+		values, ok := fields.(bson.M)
+		if !ok {
+			return fmt.Errorf("invalid update operator %s", op)
+		}
+		for path, value := range values {
+			if path == "_id" {
+				return status.Error(codes.InvalidArgument, "_id is immutable")
+			}
+			if strings.HasPrefix(path, "$") {
+				return fmt.Errorf("invalid field name %s", path)
+			}
+			switch op {
+			case "$set":
+				setPath(doc, path, value)
+			case "$unset":
+				unsetPath(doc, path)
+			default:
+				return fmt.Errorf("unsupported update operator %s", op)
+			}
+		}
+	}
+	c.docs[key] = doc
+	return nil
+}
+
+func (c *collection) delete(keys ...string) {
+	removed := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		removed[key] = struct{}{}
+		delete(c.docs, key)
+	}
+
+	remaining := c.keys[:0]
+	for _, key := range c.keys {
+		if _, ok := removed[key]; !ok {
+			remaining = append(remaining, key)
+		}
+	}
+	c.keys = remaining
+}
+
+// toDocument returns a deep copy of the given value as a bson.M, using the same codecs as the mongo driver.
+func toDocument(value interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// matchDocument supports the equality subset of the MongoDB query language, including dotted paths, and $in.
+// A nil value matches missing fields, the same way it does in MongoDB.
+// Anything else is an error rather than a silent mismatch, so a query the memory backend can't run fails loudly.
+func matchDocument(doc, filter bson.M) (bool, error) {
+	for path, expected := range filter {
+		if strings.HasPrefix(path, "$") {
+			return false, fmt.Errorf("unsupported query operator %s", path)
+		}
+		if _, ok := expected.(primitive.Regex); ok {
+			return false, fmt.Errorf("unsupported regular expression on %s", path)
+		}
+		actual, _ := lookupPath(doc, path)
+		if operators, ok := expected.(bson.M); ok && isOperatorDocument(operators) {
+			ok, err := matchOperators(actual, operators)
+			if err != nil || !ok {
+				return false, err
+			}
+			continue
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func isOperatorDocument(doc bson.M) bool {
+	for key := range doc {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperators(actual interface{}, operators bson.M) (bool, error) {
+	for op, arg := range operators {
+		switch op {
+		case "$in":
+			values, ok := arg.(bson.A)
+			if !ok {
+				return false, fmt.Errorf("$in needs an array")
+			}
+			found := false
+			for _, value := range values {
+				if reflect.DeepEqual(actual, value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false, nil
+			}
+		default:
+			return false, fmt.Errorf("unsupported query operator %s", op)
+		}
+	}
+	return true, nil
+}
+
+func lookupPath(doc bson.M, path string) (interface{}, bool) {
+	fields := strings.Split(path, ".")
+	for i, field := range fields {
+		value, ok := doc[field]
+		if !ok {
+			return nil, false
+		}
+		if i == len(fields)-1 {
+			return value, true
+		}
+		if doc, ok = value.(bson.M); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func setPath(doc bson.M, path string, value interface{}) {
+	fields := strings.Split(path, ".")
+	for _, field := range fields[:len(fields)-1] {
+		next, ok := doc[field].(bson.M)
+		if !ok {
+			next = bson.M{}
+			doc[field] = next
+		}
+		doc = next
+	}
+	doc[fields[len(fields)-1]] = value
+}
+
+func unsetPath(doc bson.M, path string) {
+	fields := strings.Split(path, ".")
+	for _, field := range fields[:len(fields)-1] {
+		next, ok := doc[field].(bson.M)
+		if !ok {
+			return
+		}
+		doc = next
+	}
+	delete(doc, fields[len(fields)-1])
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+func (r *memoryRepository) CreateApiKey(ctx context.Context, key *model.ApiKey) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateApiKey")
+	defer span.Finish()
+
+	stampTenant(ctx, &key.TenantID)
+
+	defer r.lock(ctx)()
+
+	return r.apiKeys.insert(key)
+}
+
+func (r *memoryRepository) GetApiKey(ctx context.Context, filter bson.M) (*model.ApiKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetApiKey")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.apiKeys.find(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	key := new(model.ApiKey)
+	if err := r.apiKeys.decode(keys[0], key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *memoryRepository) FindApiKeys(ctx context.Context, filter bson.M) ([]*model.ApiKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindApiKeys")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.apiKeys.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys := make([]*model.ApiKey, 0, len(keys))
+	for _, k := range keys {
+		key := new(model.ApiKey)
+		if err := r.apiKeys.decode(k, key); err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, key)
+	}
+	return apiKeys, nil
+}
+
+func (r *memoryRepository) UpdateApiKey(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateApiKey")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.apiKeys.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return r.apiKeys.update(keys[0], update)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+func (r *memoryRepository) CreateGitHubDelivery(ctx context.Context, delivery *model.GitHubDelivery) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateGitHubDelivery")
+	defer span.Finish()
+
+	stampTenant(ctx, &delivery.TenantID)
+
+	defer r.lock(ctx)()
+
+	return r.githubDeliveries.insert(delivery)
+}
+
+func (r *memoryRepository) DeleteGitHubDelivery(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteGitHubDelivery")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.githubDeliveries.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	r.githubDeliveries.delete(keys[0])
+	return nil
+}
+
+func (r *memoryRepository) PurgeGitHubDeliveries(ctx context.Context, receivedBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeGitHubDeliveries")
+	defer span.Finish()
+
+	defer r.lock(ctx)()
+
+	keys, err := r.githubDeliveries.find(scoped(ctx, bson.M{}))
+	if err != nil {
+		return 0, err
+	}
+
+	expired := make([]string, 0)
+	for _, key := range keys {
+		delivery := new(model.GitHubDelivery)
+		if err := r.githubDeliveries.decode(key, delivery); err != nil {
+			return 0, err
+		}
+		if delivery.ReceiveTime.Before(receivedBefore) {
+			expired = append(expired, key)
+		}
+	}
+	r.githubDeliveries.delete(expired...)
+	return int64(len(expired)), nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+func (r *memoryRepository) CreateIdempotencyRecord(ctx context.Context, record *model.IdempotencyRecord) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateIdempotencyRecord")
+	defer span.Finish()
+
+	stampTenant(ctx, &record.TenantID)
+
+	defer r.lock(ctx)()
+
+	return r.idempotencyRecords.insert(record)
+}
+
+func (r *memoryRepository) GetIdempotencyRecord(ctx context.Context, filter bson.M) (*model.IdempotencyRecord, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetIdempotencyRecord")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.idempotencyRecords.find(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	record := new(model.IdempotencyRecord)
+	if err := r.idempotencyRecords.decode(keys[0], record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *memoryRepository) UpdateIdempotencyRecord(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateIdempotencyRecord")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.idempotencyRecords.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return r.idempotencyRecords.update(keys[0], update)
+}
+
+func (r *memoryRepository) DeleteIdempotencyRecord(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteIdempotencyRecord")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.idempotencyRecords.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	r.idempotencyRecords.delete(keys[0])
+	return nil
+}
+
+func (r *memoryRepository) PurgeIdempotencyRecords(ctx context.Context, createdBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeIdempotencyRecords")
+	defer span.Finish()
+
+	defer r.lock(ctx)()
+
+	keys, err := r.idempotencyRecords.find(scoped(ctx, bson.M{}))
+	if err != nil {
+		return 0, err
+	}
+
+	expired := make([]string, 0)
+	for _, key := range keys {
+		record := new(model.IdempotencyRecord)
+		if err := r.idempotencyRecords.decode(key, record); err != nil {
+			return 0, err
+		}
+		if record.CreateTime.Before(createdBefore) {
+			expired = append(expired, key)
+		}
+	}
+	r.idempotencyRecords.delete(expired...)
+	return int64(len(expired)), nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+func (r *memoryRepository) CreateMember(ctx context.Context, member *model.Member) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateMember")
+	defer span.Finish()
+
+	stampTenant(ctx, &member.TenantID)
+
+	defer r.lock(ctx)()
+
+	return r.members.insert(member)
+}
+
+func (r *memoryRepository) GetMember(ctx context.Context, filter bson.M) (*model.Member, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetMember")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.members.find(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	member := new(model.Member)
+	if err := r.members.decode(keys[0], member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (r *memoryRepository) FindMembers(ctx context.Context, filter bson.M) ([]*model.Member, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindMembers")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.members.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*model.Member, 0, len(keys))
+	for _, key := range keys {
+		member := new(model.Member)
+		if err := r.members.decode(key, member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (r *memoryRepository) UpdateMember(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateMember")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.members.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return r.members.update(keys[0], update)
+}
+
+func (r *memoryRepository) DeleteMember(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteMember")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.members.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	r.members.delete(keys[0])
+	return nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+func (r *memoryRepository) CreateProject(ctx context.Context, project *model.Project) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateProject")
+	defer span.Finish()
+
+	stampTenant(ctx, &project.TenantID)
+
+	defer r.lock(ctx)()
+
+	return r.projects.insert(project)
+}
+
+func (r *memoryRepository) GetProject(ctx context.Context, filter bson.M) (*model.Project, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetProject")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.projects.find(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	project := new(model.Project)
+	if err := r.projects.decode(keys[0], project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (r *memoryRepository) FindProjects(ctx context.Context, filter bson.M) ([]*model.Project, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindProjects")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.projects.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]*model.Project, 0, len(keys))
+	for _, key := range keys {
+		project := new(model.Project)
+		if err := r.projects.decode(key, project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+func (r *memoryRepository) CountProjects(ctx context.Context, filter bson.M) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CountProjects")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.projects.find(filter)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(keys)), nil
+}
+
+func (r *memoryRepository) UpdateProject(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateProject")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.projects.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return r.projects.update(keys[0], update)
+}
+
+func (r *memoryRepository) DeleteProject(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteProject")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.projects.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	r.projects.delete(keys...)
+	return nil
+}
+
+func (r *memoryRepository) ListProjects(ctx context.Context, filter *model.ListProjectsFilter) ([]*model.Project, int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::ListProjects")
+	defer span.Finish()
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.projects.find(scoped(ctx, bson.M{}))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	projects := make([]*model.Project, 0)
+	var count int64
+	for _, key := range keys {
+		project := new(model.Project)
+		if err := r.projects.decode(key, project); err != nil {
+			return nil, 0, err
+		}
+		if !filter.Match(project) {
+			continue
+		}
+		count++
+		if filter.After(project) {
+			projects = append(projects, project)
+		}
+	}
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		return filter.Compare(projects[i], projects[j]) < 0
+	})
+
+	// One extra element tells the caller whether another page follows.
+	total := int64(len(projects))
+	if filter.Offset >= total {
+		return nil, count, nil
+	}
+	end := filter.Offset + filter.Limit + 1
+	if end > total {
+		end = total
+	}
+	return projects[filter.Offset:end], count, nil
+}
+
+func (r *memoryRepository) PurgeProjects(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeProjects")
+	defer span.Finish()
+
+	defer r.lock(ctx)()
+
+	keys, err := r.projects.find(scoped(ctx, bson.M{}))
+	if err != nil {
+		return 0, err
+	}
+
+	expired := make([]string, 0)
+	expiredIDs := make([]uuid.UUID, 0)
+	for _, key := range keys {
+		project := new(model.Project)
+		if err := r.projects.decode(key, project); err != nil {
+			return 0, err
+		}
+		if project.ExpireTime != nil && !project.ExpireTime.After(expiredBefore) {
+			expired = append(expired, key)
+			expiredIDs = append(expiredIDs, project.ID)
+		}
+	}
+
+	members, err := r.members.find(scoped(ctx, bson.M{"projectId": bson.M{"$in": expiredIDs}}))
+	if err != nil {
+		return 0, err
+	}
+	r.members.delete(members...)
+	r.projects.delete(expired...)
+	return int64(len(expired)), nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+func (r *memoryRepository) InitQuotaUsage(ctx context.Context, usage *model.QuotaUsage) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::InitQuotaUsage")
+	defer span.Finish()
+
+	stampTenant(ctx, &usage.TenantID)
+
+	defer r.lock(ctx)()
+
+	if _, _, err := r.getQuotaUsage(ctx, usage.ID); status.Code(err) != codes.NotFound {
+		return err
+	}
+	return r.quotaUsages.insert(usage)
+}
+
+func (r *memoryRepository) GetQuotaUsage(ctx context.Context, id string) (*model.QuotaUsage, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetQuotaUsage")
+	defer span.Finish()
+
+	defer r.rlock(ctx)()
+
+	_, usage, err := r.getQuotaUsage(ctx, id)
+	return usage, err
+}
+
+func (r *memoryRepository) IncrementQuotaUsage(ctx context.Context, id string, delta int64, limit int64) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::IncrementQuotaUsage")
+	defer span.Finish()
+
+	defer r.lock(ctx)()
+
+	key, usage, err := r.getQuotaUsage(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if limit > 0 && delta > 0 && usage.Usage+delta > limit {
+		return false, nil
+	}
+	return true, r.quotaUsages.update(key, bson.M{"$set": bson.M{"usage": usage.Usage + delta}})
+}
+
+// getQuotaUsage returns a usage along with its key, the caller holds the lock.
+func (r *memoryRepository) getQuotaUsage(ctx context.Context, id string) (string, *model.QuotaUsage, error) {
+	keys, err := r.quotaUsages.find(scoped(ctx, bson.M{"_id": id}))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(keys) == 0 {
+		return "", nil, status.Error(codes.NotFound, "not found")
+	}
+
+	usage := new(model.QuotaUsage)
+	if err := r.quotaUsages.decode(keys[0], usage); err != nil {
+		return "", nil, err
+	}
+	return keys[0], usage, nil
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/tenant"
+)
+
+var testTokenKey = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestProject returns a project created i hours after the start of 2026.
+func newTestProject(i int, name string, labels map[string]string) *model.Project {
+	createTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour)
+	return &model.Project{
+		ID:         uuid.Must(uuid.FromString(fmt.Sprintf("00000000-0000-4000-8000-%012d", i))),
+		Name:       name,
+		Labels:     labels,
+		CreateTime: createTime,
+		UpdateTime: createTime,
+		ETag:       model.NewETag(),
+	}
+}
+
+func TestMemoryProjectCRUD(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	repo := NewMemory()
+
+	project := newTestProject(1, "infra", map[string]string{"env": "prod"})
+	if err := repo.CreateProject(ctx, project); err != nil {
+		t.Fatalf("CreateProject() error = %v", err)
+	}
+	if project.TenantID != "acme" {
+		t.Errorf("CreateProject() stored tenant %q, want acme", project.TenantID)
+	}
+	if err := repo.CreateProject(ctx, newTestProject(1, "copy", nil)); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("CreateProject() of a duplicate id error = %v, want AlreadyExists", err)
+	}
+
+	got, err := repo.GetProject(ctx, bson.M{"_id": project.ID})
+	if err != nil {
+		t.Fatalf("GetProject() error = %v", err)
+	}
+	if got.Name != "infra" || got.Labels["env"] != "prod" || !got.CreateTime.Equal(project.CreateTime) {
+		t.Errorf("GetProject() = %+v, want the created project", got)
+	}
+
+	// The copies handed out are independent from the stored document.
+	got.Labels["env"] = "dev"
+	if again, _ := repo.GetProject(ctx, bson.M{"_id": project.ID}); again.Labels["env"] != "prod" {
+		t.Errorf("GetProject() after changing a returned project, labels = %v", again.Labels)
+	}
+
+	if _, err := repo.GetProject(tenant.NewContext(context.Background(), "other"), bson.M{"_id": project.ID}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() from another tenant error = %v, want NotFound", err)
+	}
+
+	if err := repo.UpdateProject(ctx, bson.M{"_id": project.ID}, bson.M{"$set": bson.M{"name": "core", "labels.team": "sre"}, "$unset": bson.M{"labels.env": ""}}); err != nil {
+		t.Fatalf("UpdateProject() error = %v", err)
+	}
+	got, _ = repo.GetProject(ctx, bson.M{"_id": project.ID})
+	if got.Name != "core" || got.Labels["team"] != "sre" || len(got.Labels) != 1 {
+		t.Errorf("GetProject() after update = %+v", got)
+	}
+	if err := repo.UpdateProject(ctx, bson.M{"_id": project.ID}, bson.M{"$set": bson.M{"_id": uuid.NewV4()}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdateProject() of _id error = %v, want InvalidArgument", err)
+	}
+	if err := repo.UpdateProject(ctx, bson.M{"_id": project.ID}, bson.M{"$inc": bson.M{"count": 1}}); err == nil {
+		t.Error("UpdateProject() with $inc succeeded, want an error")
+	}
+
+	if err := repo.DeleteProject(ctx, bson.M{"_id": project.ID}); err != nil {
+		t.Fatalf("DeleteProject() error = %v", err)
+	}
+	if _, err := repo.GetProject(ctx, bson.M{"_id": project.ID}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProject() after delete error = %v, want NotFound", err)
+	}
+	if err := repo.DeleteProject(ctx, bson.M{"_id": project.ID}); status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteProject() twice error = %v, want NotFound", err)
+	}
+}
+
+func TestMemoryFindProjects(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemory()
+	for i, name := range []string{"a", "b", "c"} {
+		if err := repo.CreateProject(ctx, newTestProject(i, name, map[string]string{"env": "prod"})); err != nil {
+			t.Fatalf("CreateProject() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filter  bson.M
+		want    []string
+		wantErr bool
+	}{
+		{name: "all", filter: bson.M{}, want: []string{"a", "b", "c"}},
+		{name: "equality", filter: bson.M{"name": "b"}, want: []string{"b"}},
+		{name: "dotted path", filter: bson.M{"labels.env": "prod", "name": "c"}, want: []string{"c"}},
+		{name: "in", filter: bson.M{"name": bson.M{"$in": bson.A{"a", "c", "z"}}}, want: []string{"a", "c"}},
+		{name: "missing field", filter: bson.M{"deleteTime": nil}, want: []string{"a", "b", "c"}},
+		{name: "no match", filter: bson.M{"labels.env": "dev"}, want: []string{}},
+		{name: "unsupported operator", filter: bson.M{"name": bson.M{"$ne": "a"}}, wantErr: true},
+		{name: "unsupported logical operator", filter: bson.M{"$or": bson.A{bson.M{"name": "a"}}}, wantErr: true},
+		{name: "mixed operator document", filter: bson.M{"name": bson.M{"$in": bson.A{"a"}, "x": 1}}, wantErr: true},
+		{name: "regular expression", filter: bson.M{"name": primitive.Regex{Pattern: "^a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, err := repo.FindProjects(ctx, tt.filter)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("FindProjects() = %d projects, want an error", len(projects))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindProjects() error = %v", err)
+			}
+			names := make([]string, 0, len(projects))
+			for _, p := range projects {
+				names = append(names, p.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.want) {
+				t.Errorf("FindProjects() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryListProjects(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemory()
+	for i, name := range []string{"d", "b", "a", "c", "b"} {
+		labels := map[string]string{"env": "prod"}
+		if i%2 == 1 {
+			labels = map[string]string{"env": "dev"}
+		}
+		if err := repo.CreateProject(ctx, newTestProject(i, name, labels)); err != nil {
+			t.Fatalf("CreateProject() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		req       *pb.ListProjectsRequest
+		want      []string
+		wantCount int64
+	}{
+		{name: "default order", req: &pb.ListProjectsRequest{Limit: 10}, want: []string{"d@0", "b@1", "a@2", "c@3", "b@4"}, wantCount: 5},
+		{name: "by name", req: &pb.ListProjectsRequest{Limit: 10, OrderBy: []string{"name, create_time desc"}}, want: []string{"a@2", "b@4", "b@1", "c@3", "d@0"}, wantCount: 5},
+		{name: "descending", req: &pb.ListProjectsRequest{Limit: 10, OrderBy: []string{"create_time desc"}}, want: []string{"b@4", "c@3", "a@2", "b@1", "d@0"}, wantCount: 5},
+		{name: "filter", req: &pb.ListProjectsRequest{Limit: 10, Filter: `labels.env = "dev"`}, want: []string{"b@1", "c@3"}, wantCount: 2},
+		// One element more than the limit tells the service another page follows.
+		{name: "limit", req: &pb.ListProjectsRequest{Limit: 2, OrderBy: []string{"name"}}, want: []string{"a@2", "b@1", "b@4"}, wantCount: 5},
+		{name: "offset", req: &pb.ListProjectsRequest{Limit: 2, Offset: 3, OrderBy: []string{"name"}}, want: []string{"c@3", "d@0"}, wantCount: 5},
+		{name: "offset past the end", req: &pb.ListProjectsRequest{Limit: 2, Offset: 9}, want: []string{}, wantCount: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := model.NewListProjectFilter(tt.req, testTokenKey)
+			if err != nil {
+				t.Fatalf("NewListProjectFilter() error = %v", err)
+			}
+			projects, count, err := repo.ListProjects(ctx, filter)
+			if err != nil {
+				t.Fatalf("ListProjects() error = %v", err)
+			}
+			if got := projectKeys(projects); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ListProjects() = %v, want %v", got, tt.want)
+			}
+			if count != tt.wantCount {
+				t.Errorf("ListProjects() count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestMemoryListProjectsPageTokens(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemory()
+	for i, name := range []string{"d", "b", "a", "c", "b"} {
+		if err := repo.CreateProject(ctx, newTestProject(i, name, nil)); err != nil {
+			t.Fatalf("CreateProject() error = %v", err)
+		}
+	}
+
+	req := &pb.ListProjectsRequest{Limit: 2, OrderBy: []string{"name, create_time desc"}}
+	var got []string
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination doesn't end")
+		}
+		filter, err := model.NewListProjectFilter(req, testTokenKey)
+		if err != nil {
+			t.Fatalf("NewListProjectFilter() error = %v", err)
+		}
+		projects, count, err := repo.ListProjects(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListProjects() error = %v", err)
+		}
+		page, err := model.NewPageProjects(projects, count, filter)
+		if err != nil {
+			t.Fatalf("NewPageProjects() error = %v", err)
+		}
+		got = append(got, projectKeys(page.Elements)...)
+		if page.NextPageToken == "" {
+			break
+		}
+		req.PageToken = page.NextPageToken
+	}
+
+	want := []string{"a@2", "b@4", "b@1", "c@3", "d@0"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("pages returned %v, want %v", got, want)
+	}
+}
+
+// projectKeys identifies the projects by name and creation hour.
+func projectKeys(projects []*model.Project) []string {
+	keys := make([]string, 0, len(projects))
+	for _, p := range projects {
+		keys = append(keys, fmt.Sprintf("%s@%d", p.Name, p.CreateTime.Hour()))
+	}
+	return keys
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+)
+
+func (r *memoryRepository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateWebhook")
+	defer span.Finish()
+
+	stampTenant(ctx, &webhook.TenantID)
+
+	defer r.lock(ctx)()
+
+	return r.webhooks.insert(webhook)
+}
+
+func (r *memoryRepository) GetWebhook(ctx context.Context, filter bson.M) (*model.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetWebhook")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.webhooks.find(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	webhook := new(model.Webhook)
+	if err := r.webhooks.decode(keys[0], webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *memoryRepository) FindWebhooks(ctx context.Context, filter bson.M) ([]*model.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindWebhooks")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.webhooks.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]*model.Webhook, 0, len(keys))
+	for _, key := range keys {
+		webhook := new(model.Webhook)
+		if err := r.webhooks.decode(key, webhook); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+func (r *memoryRepository) DeleteWebhook(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteWebhook")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.webhooks.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	r.webhooks.delete(keys[0])
+	return nil
+}
+
+func (r *memoryRepository) CreateDelivery(ctx context.Context, delivery *model.Delivery) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateDelivery")
+	defer span.Finish()
+
+	stampTenant(ctx, &delivery.TenantID)
+
+	defer r.lock(ctx)()
+
+	return r.deliveries.insert(delivery)
+}
+
+func (r *memoryRepository) UpdateDelivery(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateDelivery")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.deliveries.find(filter)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return r.deliveries.update(keys[0], update)
+}
+
+func (r *memoryRepository) DeleteDeliveries(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteDeliveries")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	defer r.lock(ctx)()
+
+	keys, err := r.deliveries.find(filter)
+	if err != nil {
+		return err
+	}
+	r.deliveries.delete(keys...)
+	return nil
+}
+
+func (r *memoryRepository) ListDeliveries(ctx context.Context, filter *model.ListDeliveriesFilter) ([]*model.Delivery, int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::ListDeliveries")
+	defer span.Finish()
+
+	defer r.rlock(ctx)()
+
+	keys, err := r.deliveries.find(scoped(ctx, filter.GetFilter()))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Most recent first, deliveries are inserted in creation order.
+	count := int64(len(keys))
+	deliveries := make([]*model.Delivery, 0)
+	for i := count - 1 - filter.Offset; i >= 0 && int64(len(deliveries)) < filter.Limit; i-- {
+		delivery := new(model.Delivery)
+		if err := r.deliveries.decode(keys[i], delivery); err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, count, nil
+}
+
+func (r *memoryRepository) PurgeDeliveries(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeDeliveries")
+	defer span.Finish()
+
+	defer r.lock(ctx)()
+
+	keys, err := r.deliveries.find(scoped(ctx, bson.M{
+		"state": bson.M{"$in": bson.A{pb.Delivery_SUCCEEDED.String(), pb.Delivery_DEAD_LETTER.String()}},
+	}))
+	if err != nil {
+		return 0, err
+	}
+
+	expired := make([]string, 0)
+	for _, key := range keys {
+		delivery := new(model.Delivery)
+		if err := r.deliveries.decode(key, delivery); err != nil {
+			return 0, err
+		}
+		if delivery.UpdateTime.Before(updatedBefore) {
+			expired = append(expired, key)
+		}
+	}
+	r.deliveries.delete(expired...)
+	return int64(len(expired)), nil
+}
+
+func (r *memoryRepository) ClaimDueDelivery(ctx context.Context, dueBefore time.Time, leaseUntil time.Time) (*model.Delivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::ClaimDueDelivery")
+	defer span.Finish()
+
+	defer r.lock(ctx)()
+
+	keys, err := r.deliveries.find(scoped(ctx, bson.M{"state": pb.Delivery_PENDING.String()}))
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed string
+	var due *time.Time
+	for _, key := range keys {
+		delivery := new(model.Delivery)
+		if err := r.deliveries.decode(key, delivery); err != nil {
+			return nil, err
+		}
+		next := delivery.NextAttemptTime
+		if next != nil && !next.After(dueBefore) && (due == nil || next.Before(*due)) {
+			claimed, due = key, next
+		}
+	}
+	if due == nil {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	if err := r.deliveries.update(claimed, bson.M{"$set": bson.M{"nextAttemptTime": leaseUntil}}); err != nil {
+		return nil, err
+	}
+	delivery := new(model.Delivery)
+	if err := r.deliveries.decode(claimed, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
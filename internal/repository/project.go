@@ -1,83 +1,153 @@
 package repository
 
-//
-//import (
-//	"context"
-//	"github.com/opentracing/opentracing-go"
-//	"go.mongodb.org/mongo-driver/bson"
-//	"go.mongodb.org/mongo-driver/mongo/options"
-//	"google.golang.org/grpc/codes"
-//	"google.golang.org/grpc/status"
-//	"learning/grpc-project-service/internal/model"
-//)
-//
-//const collectionProject = "project"
-//
-//type ProjectRepository interface {
-//	CreateProject(context.Context, *model.Project) error
-//	GetProject(context.Context, bson.M) (*model.Project, error)
-//	UpdateProject(ctx context.Context, filter bson.M, update bson.M) error
-//	DeleteProject(ctx context.Context, filter bson.M) error
-//	ListProjects(context.Context, *model.ListProjectsFilter) ([]*model.Project, int64, error)
-//}
-//
-//func (r *repository) UpdateProject(ctx context.Context, filter bson.M, update bson.M) error {
-//	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateProject")
-//	defer span.Finish()
-//
-//	result, err := r.MongoDatabase(ctx).Collection(collectionProject).UpdateOne(ctx, filter, update)
-//	if result != nil && result.MatchedCount == 0 {
-//		return status.Errorf(codes.NotFound, "not found")
-//	}
-//	return err
-//}
-//
-//func (r *repository) DeleteProject(ctx context.Context, filter bson.M) error {
-//	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteProject")
-//	defer span.Finish()
-//
-//	result, err := r.MongoDatabase(ctx).Collection(collectionProject).DeleteMany(ctx, filter)
-//	if result != nil && result.DeletedCount == 0 {
-//		return status.Error(codes.NotFound, "not found")
-//	}
-//	return err
-//}
-//
-//func (r *repository) GetProject(ctx context.Context, filter bson.M) (project *model.Project, err error) {
-//	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetProject")
-//	defer span.Finish()
-//
-//	err = r.MongoDatabase(ctx).Collection(collectionProject).FindOne(ctx, filter).Decode(&project)
-//	return
-//}
-//
-//func (r *repository) CreateProject(ctx context.Context, project *model.Project) error {
-//	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateProject")
-//	defer span.Finish()
-//
-//	_, err := r.MongoDatabase(ctx).Collection(collectionProject).InsertOne(ctx, *project)
-//	return err
-//}
-//
-//func (r *repository) ListProjects(ctx context.Context, filter *model.ListProjectsFilter) ([]*model.Project, int64, error) {
-//	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::ListProjects")
-//	defer span.Finish()
-//
-//	count, err := r.MongoDatabase(ctx).Collection(collectionProject).CountDocuments(ctx, filter.GetFilter())
-//	if err != nil || count == 0 {
-//		return nil, 0, err
-//	}
-//
-//	cursor, err := r.MongoDatabase(ctx).Collection(collectionProject).Find(ctx, filter.GetFilter(),
-//		options.Find().SetSort(filter.GetSort()).SetSkip(filter.Offset).SetLimit(filter.Limit))
-//	if err != nil {
-//		return nil, 0, err
-//	}
-//
-//	var projects []*model.Project
-//	if err = cursor.All(ctx, &projects); err != nil {
-//		return nil, 0, err
-//	}
-//
-//	return projects, count, nil
-//}
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+const collectionProject = "project"
+
+type ProjectRepository interface {
+	CreateProject(context.Context, *model.Project) error
+	GetProject(context.Context, bson.M) (*model.Project, error)
+	// FindProjects returns all projects matching the filter, in no particular order.
+	FindProjects(context.Context, bson.M) ([]*model.Project, error)
+	// CountProjects returns the number of projects matching the filter.
+	CountProjects(context.Context, bson.M) (int64, error)
+	UpdateProject(ctx context.Context, filter bson.M, update bson.M) error
+	DeleteProject(ctx context.Context, filter bson.M) error
+	ListProjects(context.Context, *model.ListProjectsFilter) ([]*model.Project, int64, error)
+	// PurgeProjects permanently removes soft deleted projects that expired before the given time.
+	PurgeProjects(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
+
+func (r *repository) UpdateProject(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateProject")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionProject).UpdateOne(ctx, filter, update)
+	if result != nil && result.MatchedCount == 0 {
+		return status.Errorf(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) DeleteProject(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteProject")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionProject).DeleteMany(ctx, filter)
+	if result != nil && result.DeletedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) GetProject(ctx context.Context, filter bson.M) (project *model.Project, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetProject")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	err = r.MongoDatabase(ctx).Collection(collectionProject).FindOne(ctx, filter).Decode(&project)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return
+}
+
+func (r *repository) FindProjects(ctx context.Context, filter bson.M) ([]*model.Project, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindProjects")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	cursor, err := r.MongoDatabase(ctx).Collection(collectionProject).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]*model.Project, 0)
+	if err = cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *repository) CountProjects(ctx context.Context, filter bson.M) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CountProjects")
+	defer span.Finish()
+
+	return r.MongoDatabase(ctx).Collection(collectionProject).CountDocuments(ctx, scoped(ctx, filter))
+}
+
+func (r *repository) CreateProject(ctx context.Context, project *model.Project) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateProject")
+	defer span.Finish()
+
+	stampTenant(ctx, &project.TenantID)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionProject).InsertOne(ctx, *project)
+	if mongo.IsDuplicateKeyError(err) {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	return err
+}
+
+func (r *repository) ListProjects(ctx context.Context, filter *model.ListProjectsFilter) ([]*model.Project, int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::ListProjects")
+	defer span.Finish()
+
+	count, err := r.MongoDatabase(ctx).Collection(collectionProject).CountDocuments(ctx, scoped(ctx, filter.GetFilter()))
+	if err != nil || count == 0 {
+		return nil, 0, err
+	}
+
+	// One extra element tells the caller whether another page follows.
+	cursor, err := r.MongoDatabase(ctx).Collection(collectionProject).Find(ctx, scoped(ctx, filter.GetPageFilter()),
+		options.Find().SetSort(filter.GetSort()).SetSkip(filter.Offset).SetLimit(filter.Limit+1))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var projects []*model.Project
+	if err = cursor.All(ctx, &projects); err != nil {
+		return nil, 0, err
+	}
+
+	return projects, count, nil
+}
+
+func (r *repository) PurgeProjects(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeProjects")
+	defer span.Finish()
+
+	filter := scoped(ctx, bson.M{"expireTime": bson.M{"$lte": expiredBefore}})
+	ids, err := r.MongoDatabase(ctx).Collection(collectionProject).Distinct(ctx, "_id", filter)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	// Members are deleted first, when the projects can't be deleted the next purge finds their ids again.
+	if _, err := r.MongoDatabase(ctx).Collection(collectionMember).DeleteMany(ctx, scoped(ctx, bson.M{"projectId": bson.M{"$in": ids}})); err != nil {
+		return 0, err
+	}
+	result, err := r.MongoDatabase(ctx).Collection(collectionProject).DeleteMany(ctx, scoped(ctx, bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"learning/grpc-project-service/pkg/provider"
+	"learning/grpc-project-service/pkg/provider/mongodb"
+)
+
+// Provider opens the Repository of the configured backend on Init, and is that Repository once initialized.
+// The MongoDB backend needs an initialized MongoDB Provider, the memory backend ignores it.
+type Provider struct {
+	provider.AbstractProvider
+	Repository
+
+	config          *Config
+	mongodbProvider *mongodb.MongoDB
+}
+
+// NewProvider creates a Provider, mongodbProvider may be nil unless the backend is BackendMongoDB.
+func NewProvider(config *Config, mongodbProvider *mongodb.MongoDB) *Provider {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Provider{
+		config:          config,
+		mongodbProvider: mongodbProvider,
+	}
+}
+
+// Init fails on an unknown backend, rather than falling back to one that would lose the data on restart.
+func (p *Provider) Init() error {
+	switch p.config.Backend {
+	case BackendMemory:
+		p.Repository = NewMemory()
+	case BackendMongoDB:
+		if p.mongodbProvider == nil || p.mongodbProvider.Database == nil {
+			return fmt.Errorf("repository backend %s needs an initialized MongoDB Provider", BackendMongoDB)
+		}
+		repo := &repository{mongodbProvider: p.mongodbProvider}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.mongodbProvider.Config.ConnectTimeout)
+		defer cancel()
+		if err := repo.createIndexes(ctx); err != nil {
+			return fmt.Errorf("could not create the MongoDB indexes: %w", err)
+		}
+		p.Repository = repo
+	default:
+		return fmt.Errorf("unknown repository backend %q, expected %s or %s", p.config.Backend, BackendMemory, BackendMongoDB)
+	}
+	return nil
+}
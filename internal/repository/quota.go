@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+)
+
+const collectionQuotaUsage = "quotaUsage"
+
+type QuotaUsageRepository interface {
+	// InitQuotaUsage creates a usage unless one with the same id exists, which is then left as is.
+	// It doesn't fail on an existing usage: the duplicate key error of an insert would abort a MongoDB transaction.
+	InitQuotaUsage(context.Context, *model.QuotaUsage) error
+	GetQuotaUsage(ctx context.Context, id string) (*model.QuotaUsage, error)
+	// IncrementQuotaUsage adds delta to a usage unless it would then exceed limit, 0 meaning no limit.
+	// Returns whether it was added, fails with NotFound when there is no such usage.
+	IncrementQuotaUsage(ctx context.Context, id string, delta int64, limit int64) (bool, error)
+}
+
+func (r *repository) InitQuotaUsage(ctx context.Context, usage *model.QuotaUsage) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::InitQuotaUsage")
+	defer span.Finish()
+
+	// The id and tenant of an inserted usage come from the filter.
+	filter := scoped(ctx, bson.M{"_id": usage.ID})
+	update := bson.M{"$setOnInsert": bson.M{"usage": usage.Usage}}
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionQuotaUsage).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *repository) GetQuotaUsage(ctx context.Context, id string) (usage *model.QuotaUsage, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetQuotaUsage")
+	defer span.Finish()
+
+	filter := scoped(ctx, bson.M{"_id": id})
+
+	err = r.MongoDatabase(ctx).Collection(collectionQuotaUsage).FindOne(ctx, filter).Decode(&usage)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return
+}
+
+func (r *repository) IncrementQuotaUsage(ctx context.Context, id string, delta int64, limit int64) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::IncrementQuotaUsage")
+	defer span.Finish()
+
+	filter := scoped(ctx, bson.M{"_id": id})
+	if limit > 0 && delta > 0 {
+		filter["usage"] = bson.M{"$lte": limit - delta}
+	}
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionQuotaUsage).UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"usage": delta}})
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		return true, nil
+	}
+	// Tells a missing usage apart from one that would exceed the limit.
+	if _, err := r.GetQuotaUsage(ctx, id); err != nil {
+		return false, err
+	}
+	return false, nil
+}
@@ -1,20 +1,92 @@
 package repository
 
-//import "github.azc.ext.hp.com/onecloud/lib-core-go/pkg/v1/provider/mongodb"
-//
-//type (
-//	Repository interface {
-//		mongodb.IMongoRepository
-//		ProjectRepository
-//	}
-//
-//	repository struct {
-//		mongodb.IMongoRepository
-//	}
-//)
-//
-//func New(mongodbProvider *mongodb.MongoDB) Repository {
-//	return &repository{
-//		IMongoRepository: mongodb.NewMongoRepository(mongodbProvider),
-//	}
-//}
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"learning/grpc-project-service/pkg/provider/mongodb"
+	"learning/grpc-project-service/pkg/tenant"
+)
+
+// ErrTransactionsNotSupported is returned by RunInTransaction when the backend can't apply changes atomically,
+// eg: a standalone MongoDB server.
+var ErrTransactionsNotSupported = errors.New("transactions are not supported by the repository backend")
+
+type (
+	Repository interface {
+		ProjectRepository
+		WebhookRepository
+		GitHubDeliveryRepository
+		MemberRepository
+		ApiKeyRepository
+		IdempotencyRecordRepository
+		QuotaUsageRepository
+		// RunInTransaction runs fn atomically, repository calls made with the ctx given to fn are part of the transaction.
+		// All changes are discarded when fn returns an error. fn may be retried and must not have other side effects.
+		RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	}
+
+	repository struct {
+		mongodbProvider *mongodb.MongoDB
+	}
+)
+
+// New creates a Repository backed by MongoDB.
+func New(mongodbProvider *mongodb.MongoDB) Repository {
+	return &repository{
+		mongodbProvider: mongodbProvider,
+	}
+}
+
+func (r *repository) MongoDatabase(ctx context.Context) *mongo.Database {
+	return r.mongodbProvider.Database
+}
+
+func (r *repository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := r.mongodbProvider.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+
+	// Standalone servers reject the first operation of a transaction with an IllegalOperation error.
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(20) {
+		return ErrTransactionsNotSupported
+	}
+	return err
+}
+
+// scoped returns a copy of filter restricted to the tenant of ctx, whatever the filter says about tenantId.
+// Every query and update of both backends goes through it, so the data of another tenant can't be reached even with its ids.
+// Contexts without a tenant only reach the data without one, contexts marked by tenant.Unscoped reach everything.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	if tenant.IsUnscoped(ctx) {
+		return filter
+	}
+
+	ret := make(bson.M, len(filter)+1)
+	for key, value := range filter {
+		ret[key] = value
+	}
+	if tenantID := tenant.FromContext(ctx); len(tenantID) > 0 {
+		ret["tenantId"] = tenantID
+	} else {
+		// Matches the documents without tenantId.
+		ret["tenantId"] = nil
+	}
+	return ret
+}
+
+// stampTenant sets the tenant of a new document to the one of ctx. Unscoped contexts keep the tenant of the document.
+func stampTenant(ctx context.Context, tenantID *string) {
+	if !tenant.IsUnscoped(ctx) {
+		*tenantID = tenant.FromContext(ctx)
+	}
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+)
+
+const (
+	collectionWebhook  = "webhook"
+	collectionDelivery = "delivery"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(context.Context, *model.Webhook) error
+	GetWebhook(context.Context, bson.M) (*model.Webhook, error)
+	// FindWebhooks returns all webhooks matching the filter, oldest first.
+	FindWebhooks(context.Context, bson.M) ([]*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, filter bson.M) error
+
+	CreateDelivery(context.Context, *model.Delivery) error
+	UpdateDelivery(ctx context.Context, filter bson.M, update bson.M) error
+	// DeleteDeliveries removes all deliveries matching the filter, it doesn't fail when there are none.
+	DeleteDeliveries(ctx context.Context, filter bson.M) error
+	// ListDeliveries returns a page of deliveries, most recent first, and the total number of deliveries matching the filter.
+	ListDeliveries(context.Context, *model.ListDeliveriesFilter) ([]*model.Delivery, int64, error)
+	// PurgeDeliveries removes the succeeded and dead lettered deliveries last updated before the given time.
+	PurgeDeliveries(ctx context.Context, updatedBefore time.Time) (int64, error)
+	// ClaimDueDelivery atomically moves the next attempt of the most overdue pending delivery due before dueBefore
+	// to leaseUntil, and returns it. Fails with NotFound when no delivery is due.
+	// Until the lease ends, the delivery can't be claimed again, eg: by another replica.
+	ClaimDueDelivery(ctx context.Context, dueBefore time.Time, leaseUntil time.Time) (*model.Delivery, error)
+}
+
+func (r *repository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateWebhook")
+	defer span.Finish()
+
+	stampTenant(ctx, &webhook.TenantID)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionWebhook).InsertOne(ctx, *webhook)
+	if mongo.IsDuplicateKeyError(err) {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	return err
+}
+
+func (r *repository) GetWebhook(ctx context.Context, filter bson.M) (webhook *model.Webhook, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::GetWebhook")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	err = r.MongoDatabase(ctx).Collection(collectionWebhook).FindOne(ctx, filter).Decode(&webhook)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return
+}
+
+func (r *repository) FindWebhooks(ctx context.Context, filter bson.M) ([]*model.Webhook, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::FindWebhooks")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createTime", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.MongoDatabase(ctx).Collection(collectionWebhook).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]*model.Webhook, 0)
+	if err = cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *repository) DeleteWebhook(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteWebhook")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionWebhook).DeleteOne(ctx, filter)
+	if result != nil && result.DeletedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) CreateDelivery(ctx context.Context, delivery *model.Delivery) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::CreateDelivery")
+	defer span.Finish()
+
+	stampTenant(ctx, &delivery.TenantID)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionDelivery).InsertOne(ctx, *delivery)
+	if mongo.IsDuplicateKeyError(err) {
+		return status.Error(codes.AlreadyExists, "already exists")
+	}
+	return err
+}
+
+func (r *repository) UpdateDelivery(ctx context.Context, filter bson.M, update bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::UpdateDelivery")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	result, err := r.MongoDatabase(ctx).Collection(collectionDelivery).UpdateOne(ctx, filter, update)
+	if result != nil && result.MatchedCount == 0 {
+		return status.Error(codes.NotFound, "not found")
+	}
+	return err
+}
+
+func (r *repository) DeleteDeliveries(ctx context.Context, filter bson.M) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::DeleteDeliveries")
+	defer span.Finish()
+
+	filter = scoped(ctx, filter)
+
+	_, err := r.MongoDatabase(ctx).Collection(collectionDelivery).DeleteMany(ctx, filter)
+	return err
+}
+
+func (r *repository) ListDeliveries(ctx context.Context, filter *model.ListDeliveriesFilter) ([]*model.Delivery, int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::ListDeliveries")
+	defer span.Finish()
+
+	query := scoped(ctx, filter.GetFilter())
+	collection := r.MongoDatabase(ctx).Collection(collectionDelivery)
+	count, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createTime", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(filter.Offset).
+		SetLimit(filter.Limit)
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	deliveries := make([]*model.Delivery, 0)
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, 0, err
+	}
+	return deliveries, count, nil
+}
+
+func (r *repository) PurgeDeliveries(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::PurgeDeliveries")
+	defer span.Finish()
+
+	filter := scoped(ctx, bson.M{
+		"state":      bson.M{"$in": bson.A{pb.Delivery_SUCCEEDED.String(), pb.Delivery_DEAD_LETTER.String()}},
+		"updateTime": bson.M{"$lt": updatedBefore},
+	})
+	result, err := r.MongoDatabase(ctx).Collection(collectionDelivery).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *repository) ClaimDueDelivery(ctx context.Context, dueBefore time.Time, leaseUntil time.Time) (delivery *model.Delivery, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Repository::ClaimDueDelivery")
+	defer span.Finish()
+
+	filter := scoped(ctx, bson.M{
+		"state":           pb.Delivery_PENDING.String(),
+		"nextAttemptTime": bson.M{"$lte": dueBefore},
+	})
+	update := bson.M{"$set": bson.M{"nextAttemptTime": leaseUntil}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptTime", Value: 1}}).
+		SetReturnDocument(options.After)
+	err = r.MongoDatabase(ctx).Collection(collectionDelivery).FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return
+}
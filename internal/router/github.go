@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/tenant"
+)
+
+const (
+	gitHubWebhookPattern = "/projects/{project_id}/github/webhook"
+	// Webhook of the projects of a tenant, GitHub can't send the X-Tenant-Id header.
+	gitHubTenantWebhookPattern = "/tenants/{tenant_id}/projects/{project_id}/github/webhook"
+	// GitHub caps payloads at 25MB.
+	maxGitHubPayloadSize = 25 << 20
+)
+
+// RegisterGitHubWebhookHandler routes the GitHub webhooks of projects to GitHubAPI.ReceiveGitHubEvent.
+// The signature covers the raw payload, which the generated handlers don't preserve, so the body is forwarded as is.
+// The tenant of the tenant route replaces any X-Tenant-Id header.
+func RegisterGitHubWebhookHandler(_ context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error {
+	client := pb.NewGitHubAPIClient(conn)
+
+	for _, pattern := range []string{gitHubWebhookPattern, gitHubTenantWebhookPattern} {
+		if err := mux.HandlePath(http.MethodPost, pattern, gitHubWebhookHandler(mux, client, pattern)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gitHubWebhookHandler(mux *runtime.ServeMux, client pb.GitHubAPIClient, pattern string) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		_, outboundMarshaler := runtime.MarshalerForRequest(mux, r)
+
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, "/platform.v1.GitHubAPI/ReceiveGitHubEvent", runtime.WithHTTPPathPattern(pattern))
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+		if tenantID, ok := params["tenant_id"]; ok {
+			ctx = tenant.NewOutgoingContext(ctx, tenantID)
+		}
+
+		payload, err := io.ReadAll(io.LimitReader(r.Body, maxGitHubPayloadSize+1))
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		if len(payload) > maxGitHubPayloadSize {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, status.Error(codes.InvalidArgument, "payload too large"))
+			return
+		}
+
+		var md runtime.ServerMetadata
+		resp, err := client.ReceiveGitHubEvent(ctx, &pb.ReceiveGitHubEventRequest{
+			ProjectId: params["project_id"],
+			Payload:   payload,
+		}, grpc.Header(&md.HeaderMD), grpc.Trailer(&md.TrailerMD))
+		ctx = runtime.NewServerMetadataContext(ctx, md)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		runtime.ForwardResponseMessage(ctx, mux, outboundMarshaler, w, r, resp)
+	}
+}
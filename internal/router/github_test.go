@@ -0,0 +1,142 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+// receivedEvent is a ReceiveGitHubEvent call as seen by the server.
+type receivedEvent struct {
+	req    *pb.ReceiveGitHubEventRequest
+	tenant []string
+}
+
+// gitHubServer records the events it receives, answering with ignored events unless the project id is unknown.
+type gitHubServer struct {
+	pb.UnimplementedGitHubAPIServer
+	received chan receivedEvent
+}
+
+func (s *gitHubServer) ReceiveGitHubEvent(ctx context.Context, req *pb.ReceiveGitHubEventRequest) (*pb.ReceiveGitHubEventResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.received <- receivedEvent{req: req, tenant: md.Get("x-tenant-id")}
+	if req.ProjectId == "unknown" {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+	return &pb.ReceiveGitHubEventResponse{Ignored: true}, nil
+}
+
+func newGitHubWebhookServer(t *testing.T) (*httptest.Server, *gitHubServer) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := &gitHubServer{received: make(chan receivedEvent, 1)}
+	grpcServer := grpc.NewServer()
+	pb.RegisterGitHubAPIServer(grpcServer, server)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// Forwards X-Tenant-Id as the gateway does.
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if key == "X-Tenant-Id" {
+			return "x-tenant-id", true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}))
+	if err := RegisterGitHubWebhookHandler(context.Background(), mux, conn); err != nil {
+		t.Fatalf("RegisterGitHubWebhookHandler() error = %v", err)
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, server
+}
+
+func TestGitHubWebhookHandler(t *testing.T) {
+	srv, server := newGitHubWebhookServer(t)
+	const payload = `{"zen": "Keep it logically awesome.",  "hook_id": 1}`
+
+	tests := []struct {
+		name       string
+		path       string
+		tenant     string // X-Tenant-Id header.
+		wantStatus int
+		wantTenant []string
+		wantID     string
+	}{
+		{name: "tenant route", path: "/tenants/acme/projects/p1/github/webhook", wantStatus: http.StatusOK, wantTenant: []string{"acme"}, wantID: "p1"},
+		{name: "path wins over the header", path: "/tenants/acme/projects/p1/github/webhook", tenant: "globex", wantStatus: http.StatusOK, wantTenant: []string{"acme"}, wantID: "p1"},
+		{name: "route without tenant", path: "/projects/p1/github/webhook", wantStatus: http.StatusOK, wantID: "p1"},
+		{name: "error of the service", path: "/tenants/acme/projects/unknown/github/webhook", wantStatus: http.StatusNotFound, wantTenant: []string{"acme"}, wantID: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+tt.path, strings.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.tenant != "" {
+				req.Header.Set("X-Tenant-Id", tt.tenant)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("POST error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			received := <-server.received
+			// The payload is forwarded byte for byte, the signature covers it as sent.
+			if string(received.req.Payload) != payload {
+				t.Errorf("payload = %q, want %q", received.req.Payload, payload)
+			}
+			if received.req.ProjectId != tt.wantID {
+				t.Errorf("project_id = %q, want %q", received.req.ProjectId, tt.wantID)
+			}
+			if strings.Join(received.tenant, ",") != strings.Join(tt.wantTenant, ",") {
+				t.Errorf("x-tenant-id = %v, want %v", received.tenant, tt.wantTenant)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("response isn't JSON: %v", err)
+			}
+			// Only the outcome is returned to the caller, never the project.
+			for key := range body {
+				if key != "ignored" && key != "repository" {
+					t.Errorf("response has %s, want only ignored and repository", key)
+				}
+			}
+			if body["ignored"] != true {
+				t.Errorf("response = %v, want ignored", body)
+			}
+		})
+	}
+}
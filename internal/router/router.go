@@ -26,6 +26,11 @@ func NewRouter(grpcProvider *grpc.Server, gatewayProvider *gateway.Gateway, cont
 
 func (r *Router) Init() error {
 	pb.RegisterProjectAPIServer(r.grpcProvider.Server, r.controller)
+	pb.RegisterWebhookAPIServer(r.grpcProvider.Server, r.controller)
+	pb.RegisterGitHubAPIServer(r.grpcProvider.Server, r.controller)
+	pb.RegisterMemberAPIServer(r.grpcProvider.Server, r.controller)
+	pb.RegisterApiKeyAPIServer(r.grpcProvider.Server, r.controller)
+	pb.RegisterQuotaAPIServer(r.grpcProvider.Server, r.controller)
 	return nil
 }
 
@@ -37,6 +42,12 @@ func (r *Router) Run() error {
 
 		if err := r.gatewayProvider.RegisterServices(
 			pb.RegisterProjectAPIHandler,
+			pb.RegisterWebhookAPIHandler,
+			pb.RegisterMemberAPIHandler,
+			pb.RegisterApiKeyAPIHandler,
+			pb.RegisterQuotaAPIHandler,
+			pb.RegisterGitHubAPIHandler,
+			RegisterGitHubWebhookHandler,
 		); err != nil {
 			//logging.WithError(err).Errorf("Could not register gateway service handlers")
 			return err
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/util"
+)
+
+type ApiKeyService interface {
+	CreateApiKey(context.Context, *pb.CreateApiKeyRequest) (*pb.CreateApiKeyResponse, error)
+	ListApiKeys(context.Context, *pb.ListApiKeysRequest) (*pb.ListApiKeysResponse, error)
+	RotateApiKey(context.Context, *pb.RotateApiKeyRequest) (*pb.RotateApiKeyResponse, error)
+	RevokeApiKey(context.Context, *pb.RevokeApiKeyRequest) (*pb.RevokeApiKeyResponse, error)
+	auth.ApiKeyVerifier
+}
+
+func (s *service) CreateApiKey(ctx context.Context, req *pb.CreateApiKeyRequest) (*pb.CreateApiKeyResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::CreateApiKey")
+	defer span.Finish()
+
+	if err := checkNotApiKeyCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	var owner string
+	if principal, ok := auth.FromContext(ctx); ok {
+		owner = principal.Subject
+	}
+
+	key, value, err := model.NewApiKey(req, owner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.CreateApiKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key.ToCreateApiKeyResponse(value)
+}
+
+func (s *service) ListApiKeys(ctx context.Context, _ *pb.ListApiKeysRequest) (*pb.ListApiKeysResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::ListApiKeys")
+	defer span.Finish()
+
+	if err := checkNotApiKeyCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repository.FindApiKeys(ctx, withApiKeyOwner(ctx, bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ToListApiKeysResponse(keys)
+}
+
+func (s *service) RotateApiKey(ctx context.Context, req *pb.RotateApiKeyRequest) (*pb.RotateApiKeyResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::RotateApiKey")
+	defer span.Finish()
+
+	if err := checkNotApiKeyCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	filter := withApiKeyOwner(ctx, util.WithID(uuid.FromStringOrNil(req.ApiKeyId)))
+	key, err := s.repository.GetApiKey(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokeTime != nil {
+		return nil, util.PreconditionFailure("REVOKED", "apiKeys/"+req.ApiKeyId, "revoked api keys can't be rotated")
+	}
+
+	update, value, err := model.NewApiKeyRotation()
+	if err != nil {
+		return nil, err
+	}
+	// Matching on the current hash keeps two concurrent rotations from both returning a working value.
+	filter["hash"] = key.Hash
+	if err := s.repository.UpdateApiKey(ctx, filter, update); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.Aborted, "api key was modified concurrently")
+		}
+		return nil, err
+	}
+
+	key, err = s.repository.GetApiKey(ctx, util.WithID(key.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return key.ToRotateApiKeyResponse(value)
+}
+
+func (s *service) RevokeApiKey(ctx context.Context, req *pb.RevokeApiKeyRequest) (*pb.RevokeApiKeyResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::RevokeApiKey")
+	defer span.Finish()
+
+	if err := checkNotApiKeyCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	filter := withApiKeyOwner(ctx, util.WithID(uuid.FromStringOrNil(req.ApiKeyId)))
+	key, err := s.repository.GetApiKey(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revoking twice keeps the first revoke time.
+	if key.RevokeTime == nil {
+		if err := s.repository.UpdateApiKey(ctx, filter, model.NewApiKeyRevoke()); err != nil {
+			return nil, err
+		}
+		if key, err = s.repository.GetApiKey(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	return key.ToRevokeApiKeyResponse()
+}
+
+// VerifyApiKey authenticates the callers using an API key, it records when the key was last used.
+func (s *service) VerifyApiKey(ctx context.Context, value string) (*auth.Principal, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::VerifyApiKey")
+	defer span.Finish()
+
+	prefix, ok := model.ApiKeyPrefix(value)
+	if !ok {
+		return nil, auth.ErrInvalidApiKey
+	}
+	// The key tells the tenant of the caller, so it is looked for in all of them.
+	ctx = tenant.Unscoped(ctx)
+	key, err := s.repository.GetApiKey(ctx, bson.M{"prefix": prefix})
+	if status.Code(err) == codes.NotFound {
+		return nil, auth.ErrInvalidApiKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !key.Verify(value, now) {
+		return nil, auth.ErrInvalidApiKey
+	}
+
+	// The request doesn't fail when the usage can't be recorded.
+	if err := s.repository.UpdateApiKey(ctx, util.WithID(key.ID), model.NewApiKeyUse(now)); err != nil {
+		logrus.WithError(err).WithField("api_key_id", key.ID).Warn("Could not record api key usage")
+	}
+
+	return key.ToPrincipal(), nil
+}
+
+// checkNotApiKeyCaller fails when the caller authenticated with an API key, keys can't manage keys:
+// a key could otherwise create a key without its scopes, or keep itself alive by rotating.
+func checkNotApiKeyCaller(ctx context.Context) error {
+	if principal, ok := auth.FromContext(ctx); ok && len(principal.ApiKeyID) > 0 {
+		return status.Error(codes.PermissionDenied, "api keys can't be managed with an api key")
+	}
+	return nil
+}
+
+// withApiKeyOwner restricts the filter to the keys of the authenticated caller, other keys are reported as not found.
+func withApiKeyOwner(ctx context.Context, filter bson.M) bson.M {
+	if principal, ok := auth.FromContext(ctx); ok {
+		filter["owner"] = principal.Subject
+	}
+	return filter
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opentracing/opentracing-go"
+	uuid "github.com/satori/go.uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/internal/repository"
+	"learning/grpc-project-service/pkg/util"
+)
+
+type BatchService interface {
+	BatchGetProjects(context.Context, *pb.BatchGetProjectsRequest) (*pb.BatchGetProjectsResponse, error)
+	BatchCreateProjects(context.Context, *pb.BatchCreateProjectsRequest) (*pb.BatchCreateProjectsResponse, error)
+	BatchDeleteProjects(context.Context, *pb.BatchDeleteProjectsRequest) (*pb.BatchDeleteProjectsResponse, error)
+}
+
+func (s *service) BatchGetProjects(ctx context.Context, req *pb.BatchGetProjectsRequest) (*pb.BatchGetProjectsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::BatchGetProjects")
+	defer span.Finish()
+
+	if err := s.checkBatchSize("project_ids", len(req.ProjectIds)); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.ProjectIds))
+	for _, id := range req.ProjectIds {
+		ids = append(ids, uuid.FromStringOrNil(id))
+	}
+
+	projects, err := s.repository.FindProjects(ctx, util.WithIDs(ids...))
+	if err != nil {
+		return nil, err
+	}
+	s.resolveOwners(ctx, projects...)
+
+	byID := make(map[uuid.UUID]*model.Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	resp := &pb.BatchGetProjectsResponse{Projects: make([]*pb.Project, 0, len(ids))}
+	for i, id := range ids {
+		project, ok := byID[id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "project_ids[%d]: not found", i)
+		}
+		resp.Projects = append(resp.Projects, project.ToAPI())
+	}
+	return resp, nil
+}
+
+func (s *service) BatchCreateProjects(ctx context.Context, req *pb.BatchCreateProjectsRequest) (*pb.BatchCreateProjectsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::BatchCreateProjects")
+	defer span.Finish()
+
+	if err := s.checkBatchSize("requests", len(req.Requests)); err != nil {
+		return nil, err
+	}
+
+	projects := make([]*model.Project, 0, len(req.Requests))
+	for i, r := range req.Requests {
+		project, err := model.NewProject(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.setOwner(ctx, fmt.Sprintf("requests[%d].owner_id", i), project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	ids, errs, err := s.runBatch(ctx, "requests", len(projects), func(ctx context.Context, i int) (uuid.UUID, error) {
+		err := s.withProjectQuota(ctx, projects[i].OwnerID, 1, func() error {
+			return s.repository.CreateProject(ctx, projects[i])
+		})
+		if err != nil {
+			return projects[i].ID, err
+		}
+		return projects[i].ID, s.addCreator(ctx, projects[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.findBatchResult(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, pb.WatchProjectsResponse_CREATED, created...)
+	return &pb.BatchCreateProjectsResponse{Projects: created, Errors: errs}, nil
+}
+
+func (s *service) BatchDeleteProjects(ctx context.Context, req *pb.BatchDeleteProjectsRequest) (*pb.BatchDeleteProjectsResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::BatchDeleteProjects")
+	defer span.Finish()
+
+	if err := s.checkBatchSize("project_ids", len(req.ProjectIds)); err != nil {
+		return nil, err
+	}
+
+	ids, errs, err := s.runBatch(ctx, "project_ids", len(req.ProjectIds), func(ctx context.Context, i int) (uuid.UUID, error) {
+		id := uuid.FromStringOrNil(req.ProjectIds[i])
+		return id, s.deleteProject(ctx, id, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.findBatchResult(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, pb.WatchProjectsResponse_DELETED, deleted...)
+	return &pb.BatchDeleteProjectsResponse{Projects: deleted, Errors: errs}, nil
+}
+
+func (s *service) checkBatchSize(field string, size int) error {
+	if size > s.config.MaxBatchSize {
+		return util.FieldViolation(field, fmt.Sprintf("at most %d items can be processed in a single batch", s.config.MaxBatchSize))
+	}
+	return nil
+}
+
+// runBatch applies fn to every item of a batch in a single transaction, the first failure aborts the whole batch.
+// When the repository doesn't support transactions, every item is applied on its own and failures are returned as BatchError.
+// Returns the ids of the successfully processed items, in request order.
+func (s *service) runBatch(ctx context.Context, field string, size int, fn func(ctx context.Context, i int) (uuid.UUID, error)) ([]uuid.UUID, []*pb.BatchError, error) {
+	var ids []uuid.UUID
+	failed := -1
+	err := s.repository.RunInTransaction(ctx, func(ctx context.Context) error {
+		ids = make([]uuid.UUID, 0, size)
+		for i := 0; i < size; i++ {
+			id, err := fn(ctx, i)
+			if err != nil {
+				failed = i
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err == nil {
+		return ids, nil, nil
+	}
+	if !errors.Is(err, repository.ErrTransactionsNotSupported) {
+		if failed < 0 {
+			return nil, nil, err
+		}
+		// The details are kept, eg: the QuotaFailure of the failing item.
+		st := status.Convert(err).Proto()
+		st.Message = fmt.Sprintf("%s[%d]: %s", field, failed, st.Message)
+		return nil, nil, status.FromProto(st).Err()
+	}
+
+	ids = make([]uuid.UUID, 0, size)
+	errs := make([]*pb.BatchError, 0)
+	for i := 0; i < size; i++ {
+		id, err := fn(ctx, i)
+		if err != nil {
+			errs = append(errs, &pb.BatchError{Index: int32(i), Status: status.Convert(err).Proto()})
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, errs, nil
+}
+
+// findBatchResult loads the projects processed by a batch, in the order of ids.
+func (s *service) findBatchResult(ctx context.Context, ids []uuid.UUID) ([]*pb.Project, error) {
+	if len(ids) == 0 {
+		return make([]*pb.Project, 0), nil
+	}
+
+	projects, err := s.repository.FindProjects(ctx, util.WithIDs(ids...))
+	if err != nil {
+		return nil, err
+	}
+	s.resolveOwners(ctx, projects...)
+
+	byID := make(map[uuid.UUID]*model.Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	result := make([]*pb.Project, 0, len(ids))
+	for _, id := range ids {
+		if project, ok := byID[id]; ok {
+			result = append(result, project.ToAPI())
+		}
+	}
+	return result, nil
+}
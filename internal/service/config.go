@@ -0,0 +1,154 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Config configuration for the Service.
+type Config struct {
+	// Key used to sign ListProjects page tokens. When empty a random key is generated on startup,
+	// which means tokens don't survive restarts and can't be shared between replicas.
+	PageTokenSecret string
+	// How long soft deleted projects can be restored before they are purged.
+	DeleteRetention time.Duration
+	// How often the Purger looks for expired projects.
+	PurgeInterval time.Duration
+	// Maximum number of items of a batch request.
+	MaxBatchSize int
+	// Whether the requests are authenticated, set from the configuration of the grpc Server.
+	// Without authentication projects can be created without owner.
+	AuthEnabled bool
+	// How long the request id of a CreateProject is remembered, a retry within it returns the original response.
+	// 0 disables idempotent requests, request ids are ignored.
+	IdempotencyWindow time.Duration
+
+	// source attribute of the CloudEvents sent to webhooks.
+	WebhookEventSource string
+	// How often the Dispatcher looks for deliveries to retry, 0 disables webhook deliveries.
+	WebhookPollInterval time.Duration
+	// Timeout of a single delivery attempt.
+	WebhookTimeout time.Duration
+	// Attempts made before a delivery is moved to the dead letters.
+	WebhookMaxAttempts int
+	// Delay before the first retry, doubled after every failed attempt up to WebhookMaxBackoff.
+	WebhookMinBackoff time.Duration
+	WebhookMaxBackoff time.Duration
+	// How long succeeded and dead lettered deliveries are kept, 0 keeps them forever.
+	WebhookDeliveryRetention time.Duration
+	// Lets webhooks target private, loopback and link-local addresses, eg: for local development.
+	// Otherwise they are rejected when a webhook is created and when a delivery connects to its receiver.
+	WebhookAllowPrivateTargets bool
+
+	// How long the digests of GitHub payloads are kept to reject replays.
+	GitHubDeliveryRetention time.Duration
+
+	// Default maximum number of projects of an owner and of a tenant, 0 means unlimited.
+	QuotaProjectsPerOwner  int64
+	QuotaProjectsPerTenant int64
+	// Path of a YAML file replacing the default quotas of some tenants, see QuotaOverrides.
+	QuotaOverrides string
+	// Token roles allowed to read the quota of any owner, the others can only read their own.
+	QuotaAdminRoles []string
+	// Token claim holding the roles of the caller, set from the configuration of the Authorizer.
+	RolesClaim string
+}
+
+// NewConfigFromEnv initializes the configuration from environment variables or config file.
+func NewConfigFromEnv() *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	v.SetDefault("PROJECT_DELETE_RETENTION", 30*24*time.Hour)
+	v.SetDefault("PROJECT_PURGE_INTERVAL", time.Hour)
+	v.SetDefault("MAX_BATCH_SIZE", 100)
+	v.SetDefault("IDEMPOTENCY_WINDOW", 24*time.Hour)
+	v.SetDefault("WEBHOOK_EVENT_SOURCE", "/projects")
+	v.SetDefault("WEBHOOK_POLL_INTERVAL", time.Second)
+	v.SetDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	v.SetDefault("WEBHOOK_MIN_BACKOFF", 10*time.Second)
+	v.SetDefault("WEBHOOK_MAX_BACKOFF", time.Hour)
+	v.SetDefault("WEBHOOK_DELIVERY_RETENTION", 7*24*time.Hour)
+	v.SetDefault("WEBHOOK_ALLOW_PRIVATE_TARGETS", false)
+	v.SetDefault("GITHUB_DELIVERY_RETENTION", 30*24*time.Hour)
+	v.SetDefault("QUOTA_PROJECTS_PER_OWNER", 0)
+	v.SetDefault("QUOTA_PROJECTS_PER_TENANT", 0)
+	v.SetDefault("QUOTA_ADMIN_ROLES", "admin")
+
+	config.LoadFromFile(v)
+
+	pageTokenSecret := v.GetString("PAGE_TOKEN_SECRET")
+	deleteRetention := v.GetDuration("PROJECT_DELETE_RETENTION")
+	purgeInterval := v.GetDuration("PROJECT_PURGE_INTERVAL")
+	maxBatchSize := v.GetInt("MAX_BATCH_SIZE")
+	idempotencyWindow := v.GetDuration("IDEMPOTENCY_WINDOW")
+	webhookEventSource := v.GetString("WEBHOOK_EVENT_SOURCE")
+	webhookPollInterval := v.GetDuration("WEBHOOK_POLL_INTERVAL")
+	webhookTimeout := v.GetDuration("WEBHOOK_TIMEOUT")
+	webhookMaxAttempts := v.GetInt("WEBHOOK_MAX_ATTEMPTS")
+	webhookMinBackoff := v.GetDuration("WEBHOOK_MIN_BACKOFF")
+	webhookMaxBackoff := v.GetDuration("WEBHOOK_MAX_BACKOFF")
+	webhookDeliveryRetention := v.GetDuration("WEBHOOK_DELIVERY_RETENTION")
+	webhookAllowPrivateTargets := v.GetBool("WEBHOOK_ALLOW_PRIVATE_TARGETS")
+	githubDeliveryRetention := v.GetDuration("GITHUB_DELIVERY_RETENTION")
+	quotaProjectsPerOwner := v.GetInt64("QUOTA_PROJECTS_PER_OWNER")
+	quotaProjectsPerTenant := v.GetInt64("QUOTA_PROJECTS_PER_TENANT")
+	quotaOverrides := v.GetString("QUOTA_OVERRIDES")
+
+	quotaAdminRoles := make([]string, 0)
+	for _, role := range strings.Split(v.GetString("QUOTA_ADMIN_ROLES"), ",") {
+		if role = strings.TrimSpace(role); len(role) > 0 {
+			quotaAdminRoles = append(quotaAdminRoles, role)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pageTokenSecretSet":         len(pageTokenSecret) > 0,
+		"deleteRetention":            deleteRetention,
+		"purgeInterval":              purgeInterval,
+		"maxBatchSize":               maxBatchSize,
+		"idempotencyWindow":          idempotencyWindow,
+		"webhookPollInterval":        webhookPollInterval,
+		"webhookTimeout":             webhookTimeout,
+		"webhookMaxAttempts":         webhookMaxAttempts,
+		"webhookMinBackoff":          webhookMinBackoff,
+		"webhookMaxBackoff":          webhookMaxBackoff,
+		"webhookDeliveryRetention":   webhookDeliveryRetention,
+		"webhookAllowPrivateTargets": webhookAllowPrivateTargets,
+		"githubDeliveryRetention":    githubDeliveryRetention,
+		"quotaProjectsPerOwner":      quotaProjectsPerOwner,
+		"quotaProjectsPerTenant":     quotaProjectsPerTenant,
+		"quotaOverrides":             quotaOverrides,
+		"quotaAdminRoles":            quotaAdminRoles,
+	}).Debug("Service Config Initialized")
+
+	return &Config{
+		PageTokenSecret:   pageTokenSecret,
+		DeleteRetention:   deleteRetention,
+		PurgeInterval:     purgeInterval,
+		MaxBatchSize:      maxBatchSize,
+		IdempotencyWindow: idempotencyWindow,
+
+		WebhookEventSource:         webhookEventSource,
+		WebhookPollInterval:        webhookPollInterval,
+		WebhookTimeout:             webhookTimeout,
+		WebhookMaxAttempts:         webhookMaxAttempts,
+		WebhookMinBackoff:          webhookMinBackoff,
+		WebhookMaxBackoff:          webhookMaxBackoff,
+		WebhookDeliveryRetention:   webhookDeliveryRetention,
+		WebhookAllowPrivateTargets: webhookAllowPrivateTargets,
+
+		GitHubDeliveryRetention: githubDeliveryRetention,
+
+		QuotaProjectsPerOwner:  quotaProjectsPerOwner,
+		QuotaProjectsPerTenant: quotaProjectsPerTenant,
+		QuotaOverrides:         quotaOverrides,
+		QuotaAdminRoles:        quotaAdminRoles,
+	}
+}
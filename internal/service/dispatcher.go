@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/internal/repository"
+	"learning/grpc-project-service/pkg/provider"
+	"learning/grpc-project-service/pkg/provider/broadcast"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// Maximum number of deliveries attempted at once.
+const dispatchBatchSize = 100
+
+// Dispatcher delivers project changes to the registered webhooks.
+// Every change published on the Broadcaster is stored as a pending delivery per subscribed webhook,
+// failed deliveries are retried with an exponential backoff until they run out of attempts and become dead letters.
+type Dispatcher struct {
+	provider.AbstractRunProvider
+
+	config      *Config
+	repository  repository.Repository
+	broadcaster *broadcast.Broadcaster
+	client      *http.Client
+	wake        chan struct{}
+	done        chan struct{}
+	stopped     chan struct{}
+}
+
+// NewDispatcher creates a Dispatcher, pending deliveries are looked for every config.WebhookPollInterval.
+func NewDispatcher(config *Config, repository repository.Repository, broadcaster *broadcast.Broadcaster) *Dispatcher {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Dispatcher{
+		config:      config,
+		repository:  repository,
+		broadcaster: broadcaster,
+		client:      newWebhookClient(config),
+		wake:        make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+}
+
+// newWebhookClient returns the client of the deliveries. Unless config.WebhookAllowPrivateTargets is set, it refuses to
+// connect to addresses that aren't public, redirects included, and ignores the proxy settings since it would connect to the proxy.
+func newWebhookClient(config *Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !config.WebhookAllowPrivateTargets {
+		dialer := &net.Dialer{Timeout: config.WebhookTimeout, Control: util.PublicOnlyControl}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &http.Client{Timeout: config.WebhookTimeout, Transport: transport}
+}
+
+// Run delivers events until the Dispatcher is closed.
+func (d *Dispatcher) Run() error {
+	if d.config.WebhookPollInterval <= 0 {
+		logrus.Info("Webhook Dispatcher not enabled")
+		return nil
+	}
+
+	defer close(d.stopped)
+
+	d.SetRunning(true)
+	logrus.WithField("interval", d.config.WebhookPollInterval).Info("Webhook Dispatcher launched")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		d.enqueueEvents()
+	}()
+	go func() {
+		defer wg.Done()
+		d.deliverPending()
+	}()
+	wg.Wait()
+	return nil
+}
+
+// enqueueEvents stores a delivery for every published change, until the Dispatcher or the Broadcaster is closed.
+func (d *Dispatcher) enqueueEvents() {
+	token := ""
+	for {
+		sub, err := d.broadcaster.SubscribeAll(token)
+		if errors.Is(err, broadcast.ErrTokenExpired) {
+			logrus.Warn("Webhook Dispatcher fell too far behind, some project changes won't be delivered")
+			token = ""
+			continue
+		}
+		if err != nil {
+			logrus.WithError(err).Info("Webhook Dispatcher stopped receiving project changes")
+			return
+		}
+
+		token, err = d.consume(sub)
+		if !errors.Is(err, broadcast.ErrSlowConsumer) {
+			return
+		}
+		// Resuming from the last token replays the events that were dropped.
+		logrus.Warn("Webhook Dispatcher fell behind, resuming from the last change received")
+	}
+}
+
+// consume returns the token of the last event handled and the reason the subscription ended, nil when the Dispatcher was closed.
+func (d *Dispatcher) consume(sub *broadcast.Subscription) (string, error) {
+	defer sub.Close()
+
+	var token string
+	for {
+		select {
+		case <-d.done:
+			return token, nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				return token, sub.Err()
+			}
+			if payload, ok := event.Payload.(*projectEvent); ok {
+				d.enqueue(payload)
+			}
+			token = event.Token
+		}
+	}
+}
+
+func (d *Dispatcher) enqueue(event *projectEvent) {
+	// Only the webhooks of the tenant of the project are notified.
+	ctx, cancel := context.WithTimeout(tenant.NewContext(context.Background(), event.tenantID), d.config.WebhookTimeout)
+	defer cancel()
+
+	webhooks, err := d.repository.FindWebhooks(ctx, bson.M{})
+	if err != nil {
+		logrus.WithError(err).Error("Could not find the webhooks of a project change")
+		return
+	}
+
+	var cloudEvent *model.CloudEvent
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(event.eventType) {
+			continue
+		}
+		// Every webhook receives the same event id.
+		if cloudEvent == nil {
+			if cloudEvent, err = model.NewProjectCloudEvent(d.config.WebhookEventSource, event.eventType, event.project, event.time); err != nil {
+				logrus.WithError(err).Error("Could not create the CloudEvent of a project change")
+				return
+			}
+		}
+
+		delivery, err := model.NewDelivery(webhook, cloudEvent)
+		if err == nil {
+			err = d.repository.CreateDelivery(ctx, delivery)
+		}
+		if err != nil {
+			logrus.WithError(err).WithField("webhook_id", webhook.ID).Error("Could not store webhook delivery")
+		}
+	}
+
+	if cloudEvent != nil {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// deliverPending attempts the due deliveries every poll interval, and right away when new ones are stored.
+func (d *Dispatcher) deliverPending() {
+	ticker := time.NewTicker(d.config.WebhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		d.deliverDue()
+	}
+}
+
+// deliverDue claims the due deliveries and attempts them concurrently, then waits for all of them.
+// A claimed delivery isn't due again before the end of its attempt, so neither another replica nor the next run attempt
+// it at the same time. The delivery of an attempt that was never recorded, eg: the replica stopped, is due once its lease ends.
+func (d *Dispatcher) deliverDue() {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for i := 0; i < dispatchBatchSize; i++ {
+		now := time.Now().UTC()
+		// Attempts last at most twice the timeout, see attempt.
+		ctx, cancel := context.WithTimeout(tenant.Unscoped(context.Background()), d.config.WebhookTimeout)
+		delivery, err := d.repository.ClaimDueDelivery(ctx, now, now.Add(3*d.config.WebhookTimeout))
+		cancel()
+		if status.Code(err) == codes.NotFound {
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).Error("Could not claim pending webhook delivery")
+			return
+		}
+
+		wg.Add(1)
+		go func(delivery *model.Delivery) {
+			defer wg.Done()
+			d.attempt(delivery)
+		}(delivery)
+	}
+}
+
+func (d *Dispatcher) attempt(delivery *model.Delivery) {
+	// Twice the timeout leaves room for the repository calls surrounding the request.
+	ctx, cancel := context.WithTimeout(tenant.NewContext(context.Background(), delivery.TenantID), 2*d.config.WebhookTimeout)
+	defer cancel()
+
+	logEntry := logrus.WithFields(logrus.Fields{
+		"tenant_id":   delivery.TenantID,
+		"webhook_id":  delivery.WebhookID,
+		"delivery_id": delivery.ID,
+		"attempt":     delivery.Attempts + 1,
+	})
+
+	webhook, err := d.repository.GetWebhook(ctx, util.WithID(delivery.WebhookID))
+	if status.Code(err) == codes.NotFound {
+		if err := d.repository.DeleteDeliveries(ctx, util.WithID(delivery.ID)); err != nil {
+			logEntry.WithError(err).Error("Could not remove delivery of deleted webhook")
+		}
+		return
+	}
+	if err != nil {
+		logEntry.WithError(err).Error("Could not get webhook of delivery")
+		return
+	}
+
+	statusCode, attemptErr := d.send(ctx, webhook, delivery)
+
+	var nextAttempt *time.Time
+	if attemptErr != nil {
+		if int(delivery.Attempts)+1 < d.config.WebhookMaxAttempts {
+			next := time.Now().UTC().Add(d.backoff(int(delivery.Attempts) + 1))
+			nextAttempt = &next
+			logEntry.WithError(attemptErr).WithField("next_attempt", next).Warn("Webhook delivery failed")
+		} else {
+			logEntry.WithError(attemptErr).Error("Webhook delivery failed for good, moved to the dead letters")
+		}
+	}
+
+	// Matching on the attempt count keeps the outcome of a stale attempt from overwriting a newer one.
+	filter := util.WithID(delivery.ID)
+	filter["attempts"] = delivery.Attempts
+	if err := d.repository.UpdateDelivery(ctx, filter, delivery.NewAttempt(statusCode, attemptErr, nextAttempt)); err != nil {
+		logEntry.WithError(err).Error("Could not record webhook delivery attempt")
+	}
+}
+
+// send POSTs the CloudEvent of the delivery, any non 2xx answer is a failure.
+func (d *Dispatcher) send(ctx context.Context, webhook *model.Webhook, delivery *model.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	req.Header.Set("X-Signature-256", util.SignSHA256(webhook.Secret, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Draining the body lets the connection be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the retry following the given number of attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.WebhookMinBackoff
+	for i := 1; i < attempts && delay < d.config.WebhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.config.WebhookMaxBackoff {
+		delay = d.config.WebhookMaxBackoff
+	}
+	return delay
+}
+
+// Close stops the Dispatcher, attempts in progress are finished first.
+func (d *Dispatcher) Close() error {
+	close(d.done)
+	if d.IsRunning() {
+		<-d.stopped
+	}
+	return d.AbstractRunProvider.Close()
+}
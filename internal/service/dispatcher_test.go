@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/internal/repository"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/util"
+)
+
+const (
+	testTenant        = "acme"
+	testWebhookSecret = "0123456789abcdef"
+)
+
+// receivedRequest is a delivery as seen by the receiver.
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is a webhook endpoint answering every request with the next status of its list, the last one is repeated.
+type receiver struct {
+	*httptest.Server
+	statuses []int
+	requests chan receivedRequest
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses, requests: make(chan receivedRequest, 16)}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.requests <- receivedRequest{header: req.Header.Clone(), body: body}
+
+		code := r.statuses[0]
+		if len(r.statuses) > 1 {
+			r.statuses = r.statuses[1:]
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// received returns the requests received so far.
+func (r *receiver) received() []receivedRequest {
+	var requests []receivedRequest
+	for {
+		select {
+		case req := <-r.requests:
+			requests = append(requests, req)
+		default:
+			return requests
+		}
+	}
+}
+
+func newTestConfig() *Config {
+	return &Config{
+		WebhookEventSource:         "//projects.test",
+		WebhookTimeout:             5 * time.Second,
+		WebhookMaxAttempts:         3,
+		WebhookMinBackoff:          time.Minute,
+		WebhookMaxBackoff:          4 * time.Minute,
+		WebhookAllowPrivateTargets: true, // The receivers listen on the loopback.
+	}
+}
+
+func newTestDispatcher(t *testing.T, config *Config, url string) (*Dispatcher, repository.Repository) {
+	repo := repository.NewMemory()
+	webhook := model.NewWebhook(&pb.CreateWebhookRequest{Url: url, Secret: testWebhookSecret})
+	if err := repo.CreateWebhook(tenantContext(), webhook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	return NewDispatcher(config, repo, nil), repo
+}
+
+func tenantContext() context.Context {
+	return tenant.NewContext(context.Background(), testTenant)
+}
+
+func enqueueCreated(d *Dispatcher, id string) {
+	d.enqueue(&projectEvent{
+		eventType: pb.WatchProjectsResponse_CREATED,
+		project:   &pb.Project{Id: id, Name: "Apollo"},
+		tenantID:  testTenant,
+		time:      time.Now().UTC(),
+	})
+}
+
+// onlyDelivery returns the single delivery of the repository.
+func onlyDelivery(t *testing.T, repo repository.Repository) *model.Delivery {
+	t.Helper()
+	deliveries, total, err := repo.ListDeliveries(tenantContext(), &model.ListDeliveriesFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListDeliveries: %v", err)
+	}
+	if total != 1 {
+		t.Fatalf("got %d deliveries, want 1", total)
+	}
+	return deliveries[0]
+}
+
+// makeDue moves the next attempt of a delivery to the past, as if its backoff had elapsed.
+func makeDue(t *testing.T, repo repository.Repository, delivery *model.Delivery) {
+	t.Helper()
+	past := time.Now().UTC().Add(-time.Second)
+	if err := repo.UpdateDelivery(tenantContext(), util.WithID(delivery.ID), util.WithUpdate(bson.M{"nextAttemptTime": past})); err != nil {
+		t.Fatalf("UpdateDelivery: %v", err)
+	}
+}
+
+func TestDispatcherSendsSignedCloudEvent(t *testing.T) {
+	r := newReceiver(t, http.StatusNoContent)
+	d, repo := newTestDispatcher(t, newTestConfig(), r.URL)
+
+	enqueueCreated(d, "3f1c5a1e-0000-4000-8000-000000000001")
+	d.deliverDue()
+
+	requests := r.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+
+	if got := req.header.Get("Content-Type"); got != "application/cloudevents+json" {
+		t.Errorf("Content-Type = %q, want application/cloudevents+json", got)
+	}
+
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write(req.body)
+	if got, want := req.header.Get("X-Signature-256"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("X-Signature-256 = %q, want %q", got, want)
+	}
+
+	var event struct {
+		SpecVersion     string `json:"specversion"`
+		ID              string `json:"id"`
+		Source          string `json:"source"`
+		Type            string `json:"type"`
+		Subject         string `json:"subject"`
+		Time            string `json:"time"`
+		DataContentType string `json:"datacontenttype"`
+		Data            struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &event); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if event.SpecVersion != "1.0" || event.Source != "//projects.test" || event.Type != "platform.v1.project.created" ||
+		event.DataContentType != "application/json" {
+		t.Errorf("unexpected CloudEvent attributes: %+v", event)
+	}
+	if event.Subject != "projects/3f1c5a1e-0000-4000-8000-000000000001" || event.Data.ID != "3f1c5a1e-0000-4000-8000-000000000001" ||
+		event.Data.Name != "Apollo" {
+		t.Errorf("CloudEvent doesn't describe the project: %+v", event)
+	}
+	if len(event.ID) == 0 || len(event.Time) == 0 {
+		t.Errorf("CloudEvent misses its id or time: %+v", event)
+	}
+
+	delivery := onlyDelivery(t, repo)
+	if delivery.State != pb.Delivery_SUCCEEDED.String() || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusNoContent {
+		t.Errorf("delivery = %s after %d attempts with %d, want SUCCEEDED after 1 with 204", delivery.State, delivery.Attempts, delivery.LastStatusCode)
+	}
+	if delivery.EventID != event.ID || delivery.NextAttemptTime != nil {
+		t.Errorf("delivery event id = %q, next attempt = %v, want %q and none", delivery.EventID, delivery.NextAttemptTime, event.ID)
+	}
+}
+
+func TestDispatcherRetriesFailedDelivery(t *testing.T) {
+	r := newReceiver(t, http.StatusInternalServerError, http.StatusOK)
+	config := newTestConfig()
+	d, repo := newTestDispatcher(t, config, r.URL)
+
+	enqueueCreated(d, "3f1c5a1e-0000-4000-8000-000000000002")
+	before := time.Now().UTC()
+	d.deliverDue()
+
+	delivery := onlyDelivery(t, repo)
+	if delivery.State != pb.Delivery_PENDING.String() || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("delivery = %s after %d attempts with %d, want PENDING after 1 with 500", delivery.State, delivery.Attempts, delivery.LastStatusCode)
+	}
+	if len(delivery.LastError) == 0 {
+		t.Error("failed attempt recorded without its error")
+	}
+	if delivery.NextAttemptTime == nil {
+		t.Fatal("failed delivery has no next attempt")
+	}
+	// Stored times are truncated to the millisecond.
+	if next := delivery.NextAttemptTime.Sub(before); next < config.WebhookMinBackoff-time.Millisecond || next > config.WebhookMinBackoff+time.Minute {
+		t.Errorf("next attempt in %s, want about %s", next, config.WebhookMinBackoff)
+	}
+
+	// Not due before its backoff elapsed.
+	d.deliverDue()
+	if got := len(r.received()); got != 1 {
+		t.Fatalf("receiver got %d requests before the backoff elapsed, want 1", got)
+	}
+
+	makeDue(t, repo, delivery)
+	d.deliverDue()
+	if got := len(r.received()); got != 1 {
+		t.Fatalf("receiver got %d retries, want 1", got)
+	}
+
+	delivery = onlyDelivery(t, repo)
+	if delivery.State != pb.Delivery_SUCCEEDED.String() || delivery.Attempts != 2 || len(delivery.LastError) > 0 {
+		t.Errorf("delivery = %s after %d attempts (%q), want SUCCEEDED after 2", delivery.State, delivery.Attempts, delivery.LastError)
+	}
+}
+
+func TestDispatcherDeadLettersDelivery(t *testing.T) {
+	r := newReceiver(t, http.StatusServiceUnavailable)
+	config := newTestConfig()
+	d, repo := newTestDispatcher(t, config, r.URL)
+
+	enqueueCreated(d, "3f1c5a1e-0000-4000-8000-000000000003")
+	for i := 0; i < config.WebhookMaxAttempts; i++ {
+		d.deliverDue()
+		makeDue(t, repo, onlyDelivery(t, repo))
+	}
+
+	delivery := onlyDelivery(t, repo)
+	if delivery.State != pb.Delivery_DEAD_LETTER.String() || int(delivery.Attempts) != config.WebhookMaxAttempts {
+		t.Fatalf("delivery = %s after %d attempts, want DEAD_LETTER after %d", delivery.State, delivery.Attempts, config.WebhookMaxAttempts)
+	}
+	if delivery.LastStatusCode != http.StatusServiceUnavailable || len(delivery.LastError) == 0 {
+		t.Errorf("last attempt recorded as %d (%q), want 503 and its error", delivery.LastStatusCode, delivery.LastError)
+	}
+
+	// Dead letters are never attempted again, even when their next attempt time was set.
+	d.deliverDue()
+	if got := len(r.received()); got != config.WebhookMaxAttempts {
+		t.Errorf("receiver got %d requests, want %d", got, config.WebhookMaxAttempts)
+	}
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := NewDispatcher(newTestConfig(), nil, nil)
+
+	for attempts, want := range map[int]time.Duration{
+		1: time.Minute,
+		2: 2 * time.Minute,
+		3: 4 * time.Minute,
+		4: 4 * time.Minute, // Capped to WebhookMaxBackoff.
+		9: 4 * time.Minute,
+	} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestClaimDueDeliveryLeasesDelivery(t *testing.T) {
+	r := newReceiver(t, http.StatusOK)
+	d, repo := newTestDispatcher(t, newTestConfig(), r.URL)
+	enqueueCreated(d, "3f1c5a1e-0000-4000-8000-000000000004")
+
+	ctx := tenant.Unscoped(context.Background())
+	now := time.Now().UTC()
+	claimed, err := repo.ClaimDueDelivery(ctx, now, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("ClaimDueDelivery: %v", err)
+	}
+	if claimed.NextAttemptTime == nil || claimed.NextAttemptTime.Before(now.Add(59*time.Second)) {
+		t.Errorf("claimed delivery next attempt = %v, want the end of its lease", claimed.NextAttemptTime)
+	}
+
+	// Another replica doesn't get it while the lease runs.
+	if _, err := repo.ClaimDueDelivery(ctx, now, now.Add(time.Minute)); status.Code(err) != codes.NotFound {
+		t.Errorf("second claim = %v, want NotFound", err)
+	}
+	d.deliverDue()
+	if got := len(r.received()); got != 0 {
+		t.Errorf("receiver got %d requests of a leased delivery, want 0", got)
+	}
+
+	// It's due again once the lease ended, eg: the replica that claimed it stopped.
+	later := now.Add(2 * time.Minute)
+	if _, err := repo.ClaimDueDelivery(ctx, later, later.Add(time.Minute)); err != nil {
+		t.Errorf("claim after the lease = %v, want the delivery", err)
+	}
+}
+
+func TestDispatcherRefusesPrivateTargets(t *testing.T) {
+	r := newReceiver(t, http.StatusOK)
+	config := newTestConfig()
+	config.WebhookAllowPrivateTargets = false
+	d, repo := newTestDispatcher(t, config, r.URL)
+
+	enqueueCreated(d, "3f1c5a1e-0000-4000-8000-000000000005")
+	d.deliverDue()
+
+	if got := len(r.received()); got != 0 {
+		t.Errorf("receiver on the loopback got %d requests, want 0", got)
+	}
+	delivery := onlyDelivery(t, repo)
+	if delivery.State != pb.Delivery_PENDING.String() || !strings.Contains(delivery.LastError, util.ErrPrivateAddress.Error()) {
+		t.Errorf("delivery = %s (%q), want PENDING failing on the private address", delivery.State, delivery.LastError)
+	}
+}
+
+func TestCreateWebhookRefusesPrivateTargets(t *testing.T) {
+	s := New(&Config{}, repository.NewMemory(), nil)
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+	} {
+		_, err := s.CreateWebhook(tenantContext(), &pb.CreateWebhookRequest{Url: url, Secret: testWebhookSecret})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("CreateWebhook(%s) = %v, want InvalidArgument", url, err)
+		}
+	}
+
+	s = New(&Config{WebhookAllowPrivateTargets: true}, repository.NewMemory(), nil)
+	if _, err := s.CreateWebhook(tenantContext(), &pb.CreateWebhookRequest{Url: "http://127.0.0.1:8080/hook", Secret: testWebhookSecret}); err != nil {
+		t.Errorf("CreateWebhook with private targets allowed = %v, want no error", err)
+	}
+}
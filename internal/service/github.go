@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/util"
+)
+
+type GitHubService interface {
+	ReceiveGitHubEvent(context.Context, *pb.ReceiveGitHubEventRequest) (*pb.ReceiveGitHubEventResponse, error)
+	RotateGitHubWebhookSecret(context.Context, *pb.RotateGitHubWebhookSecretRequest) (*pb.RotateGitHubWebhookSecretResponse, error)
+}
+
+func (s *service) ReceiveGitHubEvent(ctx context.Context, req *pb.ReceiveGitHubEventRequest) (*pb.ReceiveGitHubEventResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::ReceiveGitHubEvent")
+	defer span.Finish()
+
+	// The tenant comes from the path of the webhook, a project of another tenant is never reached.
+	id := uuid.FromStringOrNil(req.ProjectId)
+	project, err := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(id)))
+	if err != nil {
+		return nil, err
+	}
+	if len(project.GitHubWebhookSecret) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "the project has no GitHub webhook secret, see RotateGitHubWebhookSecret")
+	}
+	if !util.VerifySHA256(project.GitHubWebhookSecret, req.Payload, req.Signature) {
+		return nil, status.Error(codes.PermissionDenied, "signature doesn't match the payload")
+	}
+
+	event, err := model.ParseGitHubEvent(req.Payload)
+	if err != nil {
+		return nil, util.FieldViolation("payload", err.Error())
+	}
+
+	delivery := model.NewGitHubDelivery(id, req)
+	if err := s.repository.CreateGitHubDelivery(ctx, delivery); err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return nil, status.Error(codes.AlreadyExists, "payload was already received")
+		}
+		return nil, err
+	}
+
+	if !project.ApplyGitHubEvent(req.Event, event, time.Now().UTC()) {
+		return project.ToReceiveGitHubEventResponse(true, 0)
+	}
+
+	filter := util.WithETag(util.WithoutDeleted(util.WithID(id)), project.ETag)
+	if err := s.repository.UpdateProject(ctx, filter, model.NewGitHubRepositoriesUpdate(project.GitHubRepositories)); err != nil {
+		// Forgetting the payload lets GitHub redeliver it.
+		if err := s.repository.DeleteGitHubDelivery(ctx, bson.M{"_id": delivery.ID}); err != nil {
+			logrus.WithError(err).WithField("delivery_id", delivery.DeliveryID).Error("Could not forget failed GitHub delivery")
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.Aborted, "project was modified concurrently")
+		}
+		return nil, err
+	}
+
+	project, err = s.repository.GetProject(ctx, util.WithID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	s.resolveOwners(ctx, project)
+	s.publish(ctx, pb.WatchProjectsResponse_UPDATED, project.ToAPI())
+
+	return project.ToReceiveGitHubEventResponse(false, event.Repository.ID)
+}
+
+func (s *service) RotateGitHubWebhookSecret(ctx context.Context, req *pb.RotateGitHubWebhookSecretRequest) (*pb.RotateGitHubWebhookSecretResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::RotateGitHubWebhookSecret")
+	defer span.Finish()
+
+	secret, update, err := model.NewGitHubWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.FromStringOrNil(req.ProjectId)
+	if err := s.repository.UpdateProject(ctx, util.WithoutDeleted(util.WithID(id)), update); err != nil {
+		return nil, err
+	}
+
+	return model.ToRotateGitHubWebhookSecretResponse(secret, model.GitHubWebhookPath(tenant.FromContext(ctx), id))
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/util"
+)
+
+const testPushPayload = `{"ref":"refs/heads/main","after":"9f2c","repository":{"id":42,"full_name":"acme/infra","html_url":"https://github.com/acme/infra","default_branch":"main"}}`
+
+// createGitHubProject creates a project of the test tenant and returns it with its webhook secret.
+func createGitHubProject(t *testing.T, s *service) (*model.Project, string) {
+	t.Helper()
+	project, _ := model.NewProject(&pb.CreateProjectRequest{Name: "infra"})
+	if err := s.repository.CreateProject(tenantContext(), project); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	resp, err := s.RotateGitHubWebhookSecret(tenantContext(), &pb.RotateGitHubWebhookSecretRequest{ProjectId: project.ID.String()})
+	if err != nil {
+		t.Fatalf("RotateGitHubWebhookSecret: %v", err)
+	}
+	return project, resp.Secret
+}
+
+func signGitHubPayload(secret string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestRotateGitHubWebhookSecret(t *testing.T) {
+	s := newTestService(t, newTestConfig())
+	project, first := createGitHubProject(t, s)
+
+	resp, err := s.RotateGitHubWebhookSecret(tenantContext(), &pb.RotateGitHubWebhookSecretRequest{ProjectId: project.ID.String()})
+	if err != nil {
+		t.Fatalf("RotateGitHubWebhookSecret: %v", err)
+	}
+	if len(resp.Secret) == 0 || resp.Secret == first {
+		t.Errorf("secret = %q after rotating %q, want a new one", resp.Secret, first)
+	}
+	if want := "/tenants/acme/projects/" + project.ID.String() + "/github/webhook"; resp.WebhookPath != want {
+		t.Errorf("webhook_path = %q, want %q", resp.WebhookPath, want)
+	}
+
+	stored, err := s.repository.GetProject(tenantContext(), util.WithID(project.ID))
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if stored.GitHubWebhookSecret != resp.Secret {
+		t.Errorf("stored secret = %q, want %q", stored.GitHubWebhookSecret, resp.Secret)
+	}
+	if stored.ETag != project.ETag {
+		t.Errorf("etag changed from %q to %q, the secret isn't part of the project", project.ETag, stored.ETag)
+	}
+
+	// The previous secret stops working right away.
+	_, err = s.ReceiveGitHubEvent(tenantContext(), &pb.ReceiveGitHubEventRequest{
+		ProjectId: project.ID.String(), Event: "push", DeliveryId: "d1",
+		Signature: signGitHubPayload(first, testPushPayload), Payload: []byte(testPushPayload),
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ReceiveGitHubEvent signed with the previous secret: %v, want PermissionDenied", err)
+	}
+
+	other := tenant.NewContext(context.Background(), "globex")
+	if _, err := s.RotateGitHubWebhookSecret(other, &pb.RotateGitHubWebhookSecretRequest{ProjectId: project.ID.String()}); status.Code(err) != codes.NotFound {
+		t.Errorf("RotateGitHubWebhookSecret from another tenant: %v, want NotFound", err)
+	}
+}
+
+func TestReceiveGitHubEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		event       string
+		payload     string
+		secret      string // Signing secret, the one of the project when empty.
+		noSecret    bool   // Whether the project never got a secret.
+		wantCode    codes.Code
+		wantIgnored bool
+	}{
+		{name: "push links the repository", event: "push", payload: testPushPayload},
+		{name: "other event ignored", event: "issues", payload: testPushPayload, wantIgnored: true},
+		{name: "event without repository ignored", event: "push", payload: `{"ref":"refs/heads/main"}`, wantIgnored: true},
+		{name: "wrong secret", event: "push", payload: testPushPayload, secret: "guessed", wantCode: codes.PermissionDenied},
+		{name: "project without secret", event: "push", payload: testPushPayload, secret: "guessed", noSecret: true, wantCode: codes.FailedPrecondition},
+		{name: "project of another tenant", ctx: tenant.NewContext(context.Background(), "globex"), event: "push", payload: testPushPayload, wantCode: codes.NotFound},
+		{name: "project without tenant", ctx: context.Background(), event: "push", payload: testPushPayload, wantCode: codes.NotFound},
+		{name: "invalid payload", event: "push", payload: `{`, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, newTestConfig())
+			var project *model.Project
+			var secret string
+			if tt.noSecret {
+				project, _ = model.NewProject(&pb.CreateProjectRequest{Name: "infra"})
+				if err := s.repository.CreateProject(tenantContext(), project); err != nil {
+					t.Fatalf("CreateProject: %v", err)
+				}
+			} else {
+				project, secret = createGitHubProject(t, s)
+			}
+			if len(tt.secret) > 0 {
+				secret = tt.secret
+			}
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = tenantContext()
+			}
+
+			resp, err := s.ReceiveGitHubEvent(ctx, &pb.ReceiveGitHubEventRequest{
+				ProjectId: project.ID.String(), Event: tt.event, DeliveryId: "d1",
+				Signature: signGitHubPayload(secret, tt.payload), Payload: []byte(tt.payload),
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ReceiveGitHubEvent: %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Ignored != tt.wantIgnored {
+				t.Errorf("ignored = %v, want %v", resp.Ignored, tt.wantIgnored)
+			}
+			if tt.wantIgnored {
+				if resp.Repository != nil {
+					t.Errorf("repository = %v for an ignored event, want none", resp.Repository)
+				}
+				return
+			}
+			if resp.Repository.GetFullName() != "acme/infra" || resp.Repository.GetHeadCommit() != "9f2c" {
+				t.Errorf("repository = %v, want acme/infra at 9f2c", resp.Repository)
+			}
+		})
+	}
+}
+
+func TestReceiveGitHubEventRejectsReplays(t *testing.T) {
+	s := newTestService(t, newTestConfig())
+	project, secret := createGitHubProject(t, s)
+
+	receive := func(deliveryID string) error {
+		_, err := s.ReceiveGitHubEvent(tenantContext(), &pb.ReceiveGitHubEventRequest{
+			ProjectId: project.ID.String(), Event: "push", DeliveryId: deliveryID,
+			Signature: signGitHubPayload(secret, testPushPayload), Payload: []byte(testPushPayload),
+		})
+		return err
+	}
+
+	if err := receive("d1"); err != nil {
+		t.Fatalf("ReceiveGitHubEvent: %v", err)
+	}
+	// The delivery id isn't signed, a captured payload re-sent under a fresh one is still a replay.
+	for _, deliveryID := range []string{"d1", "d2"} {
+		if err := receive(deliveryID); status.Code(err) != codes.AlreadyExists {
+			t.Errorf("ReceiveGitHubEvent replayed as %s: %v, want AlreadyExists", deliveryID, err)
+		}
+	}
+
+	// The same payload is still accepted by another project, with its own secret.
+	other, otherSecret := createGitHubProject(t, s)
+	_, err := s.ReceiveGitHubEvent(tenantContext(), &pb.ReceiveGitHubEventRequest{
+		ProjectId: other.ID.String(), Event: "push", DeliveryId: "d3",
+		Signature: signGitHubPayload(otherSecret, testPushPayload), Payload: []byte(testPushPayload),
+	})
+	if err != nil {
+		t.Errorf("ReceiveGitHubEvent for another project: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/tenant"
+)
+
+const (
+	// Requests in progress for longer are considered abandoned, eg: the replica handling them stopped, their record is taken over.
+	idempotencyReservationTimeout = time.Minute
+	// Times the record of a request id is looked for when it keeps being replaced.
+	maxReserveAttempts = 3
+)
+
+// idempotencyRecordID returns the id of the record of a request id, empty when there is no request id
+// or idempotent requests are disabled. Request ids are only shared by the requests of the same caller.
+func (s *service) idempotencyRecordID(ctx context.Context, requestID string) string {
+	if len(requestID) == 0 || s.config.IdempotencyWindow <= 0 {
+		return ""
+	}
+
+	var caller string
+	if principal, ok := auth.FromContext(ctx); ok {
+		caller = principal.Subject
+	}
+	return model.IdempotencyRecordID(tenant.FromContext(ctx), caller, requestID)
+}
+
+// reserveCreateProject stores the record of a request in progress before it creates anything, so a concurrent retry
+// can't create another project. Returns the recorded response when the request id was already used within the idempotency
+// window, nil when it was reserved for this request.
+// Fails with AlreadyExists when it was used by a request with other fields, and Aborted while that request is in progress.
+func (s *service) reserveCreateProject(ctx context.Context, recordID string, req *pb.CreateProjectRequest) (*pb.CreateProjectResponse, error) {
+	reservation, err := model.NewIdempotencyRecord(recordID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		err := s.repository.CreateIdempotencyRecord(ctx, reservation)
+		if status.Code(err) != codes.AlreadyExists {
+			return nil, err
+		}
+
+		record, err := s.repository.GetIdempotencyRecord(ctx, bson.M{"_id": recordID})
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now().UTC()
+		expired := record.CreateTime.Before(now.Add(-s.config.IdempotencyWindow))
+		abandoned := record.InProgress() && record.CreateTime.Before(now.Add(-idempotencyReservationTimeout))
+		if expired || abandoned {
+			// Matching on the creation time, only one of concurrent retries removes it, the others find its reservation.
+			err := s.repository.DeleteIdempotencyRecord(ctx, bson.M{"_id": recordID, "createTime": record.CreateTime})
+			if err != nil && status.Code(err) != codes.NotFound {
+				return nil, err
+			}
+			continue
+		}
+
+		if record.RequestHash != reservation.RequestHash {
+			return nil, status.Error(codes.AlreadyExists, "request_id was already used by a different request")
+		}
+		if record.InProgress() {
+			return nil, status.Error(codes.Aborted, "the request with the same request_id is still in progress, retry later")
+		}
+		return record.ToCreateProjectResponse()
+	}
+	return nil, status.Error(codes.Aborted, "request_id is being used concurrently, retry later")
+}
+
+// releaseCreateProject removes the record of a request that failed, so it can be retried. Failures are only logged,
+// the record is then taken over by the next retry once idempotencyReservationTimeout elapsed.
+func (s *service) releaseCreateProject(ctx context.Context, recordID string) {
+	if err := s.repository.DeleteIdempotencyRecord(ctx, bson.M{"_id": recordID}); err != nil {
+		logrus.WithError(err).Warn("Could not release request_id of failed request")
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/util"
+)
+
+const testRequestID = "req-1"
+
+func newTestIdempotencyService(t *testing.T) *service {
+	config := newTestConfig()
+	config.IdempotencyWindow = time.Hour
+	return newTestService(t, config)
+}
+
+// storeIdempotencyRecord stores the record of req as if it was made at createTime, with resp as its recorded response
+// or in progress when resp is nil.
+func storeIdempotencyRecord(t *testing.T, s *service, req *pb.CreateProjectRequest, createTime time.Time, resp *pb.CreateProjectResponse) {
+	t.Helper()
+	recordID := s.idempotencyRecordID(tenantContext(), req.RequestId)
+	record, err := model.NewIdempotencyRecord(recordID, req)
+	if err != nil {
+		t.Fatalf("NewIdempotencyRecord: %v", err)
+	}
+	record.CreateTime = createTime
+	if err := s.repository.CreateIdempotencyRecord(tenantContext(), record); err != nil {
+		t.Fatalf("CreateIdempotencyRecord: %v", err)
+	}
+	if resp == nil {
+		return
+	}
+	update, err := model.NewIdempotencyRecordResponse(resp)
+	if err != nil {
+		t.Fatalf("NewIdempotencyRecordResponse: %v", err)
+	}
+	if err := s.repository.UpdateIdempotencyRecord(tenantContext(), bson.M{"_id": recordID}, update); err != nil {
+		t.Fatalf("UpdateIdempotencyRecord: %v", err)
+	}
+}
+
+// countProjects returns the number of projects of the test tenant.
+func countProjects(t *testing.T, s *service) int64 {
+	t.Helper()
+	count, err := s.repository.CountProjects(tenantContext(), util.WithoutDeleted(bson.M{}))
+	if err != nil {
+		t.Fatalf("CountProjects: %v", err)
+	}
+	return count
+}
+
+func TestCreateProjectReplay(t *testing.T) {
+	s := newTestIdempotencyService(t)
+	req := &pb.CreateProjectRequest{Name: "infra", OwnerId: testOwner, RequestId: testRequestID}
+
+	first, err := s.CreateProject(tenantContext(), req)
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	replayed, err := s.CreateProject(tenantContext(), req)
+	if err != nil {
+		t.Fatalf("CreateProject retried: %v", err)
+	}
+	if replayed.Project.Id != first.Project.Id || replayed.Project.Etag != first.Project.Etag {
+		t.Errorf("retry returned project %s, want the first response %s", replayed.Project.Id, first.Project.Id)
+	}
+	// The owner name isn't recorded, it is resolved again.
+	if replayed.Project.OwnerName != testOwner {
+		t.Errorf("owner_name = %q, want %q", replayed.Project.OwnerName, testOwner)
+	}
+	if count := countProjects(t, s); count != 1 {
+		t.Errorf("%d projects created, want 1", count)
+	}
+
+	// Request ids are only shared within a tenant.
+	other := tenant.NewContext(context.Background(), "globex")
+	resp, err := s.CreateProject(other, req)
+	if err != nil {
+		t.Fatalf("CreateProject in another tenant: %v", err)
+	}
+	if resp.Project.Id == first.Project.Id {
+		t.Error("the request id of another tenant returned its project")
+	}
+}
+
+func TestCreateProjectReservations(t *testing.T) {
+	req := &pb.CreateProjectRequest{Name: "infra", OwnerId: testOwner, RequestId: testRequestID}
+	recorded := &pb.CreateProjectResponse{Project: &pb.Project{Id: "3f1c5a1e-0000-4000-8000-0000000000aa", Name: "infra"}}
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name        string
+		recorded    *pb.CreateProjectRequest // Request of the stored record.
+		createTime  time.Time
+		response    *pb.CreateProjectResponse
+		wantCode    codes.Code
+		wantCreated bool // Whether the request creates a project, rather than replaying the recorded one.
+	}{
+		{name: "completed", recorded: req, createTime: now, response: recorded},
+		{name: "different request", recorded: &pb.CreateProjectRequest{Name: "other", RequestId: testRequestID}, createTime: now, response: recorded, wantCode: codes.AlreadyExists},
+		{name: "in progress", recorded: req, createTime: now, wantCode: codes.Aborted},
+		{name: "abandoned reservation taken over", recorded: req, createTime: now.Add(-2 * idempotencyReservationTimeout), wantCreated: true},
+		{name: "outside the window", recorded: req, createTime: now.Add(-2 * time.Hour), response: recorded, wantCreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestIdempotencyService(t)
+			storeIdempotencyRecord(t, s, tt.recorded, tt.createTime, tt.response)
+
+			resp, err := s.CreateProject(tenantContext(), req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("CreateProject: %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				if count := countProjects(t, s); count != 0 {
+					t.Errorf("%d projects created, want none", count)
+				}
+				return
+			}
+
+			created := resp.Project.Id != recorded.Project.Id
+			if created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+			if !created {
+				return
+			}
+			// The new response replaces the record.
+			replayed, err := s.CreateProject(tenantContext(), req)
+			if err != nil {
+				t.Fatalf("CreateProject retried: %v", err)
+			}
+			if replayed.Project.Id != resp.Project.Id {
+				t.Errorf("retry returned project %s, want %s", replayed.Project.Id, resp.Project.Id)
+			}
+		})
+	}
+}
+
+func TestCreateProjectFailureReleasesRequestID(t *testing.T) {
+	s := newTestIdempotencyService(t)
+	s.config.QuotaProjectsPerOwner = 1
+
+	existing, err := s.CreateProject(tenantContext(), &pb.CreateProjectRequest{Name: "infra", OwnerId: testOwner})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	req := &pb.CreateProjectRequest{Name: "core", OwnerId: testOwner, RequestId: testRequestID}
+	if _, err := s.CreateProject(tenantContext(), req); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("CreateProject beyond the quota: %v, want ResourceExhausted", err)
+	}
+
+	// The failed request didn't keep its request id, the retry is processed once the quota allows it.
+	if _, err := s.DeleteProject(tenantContext(), &pb.DeleteProjectRequest{ProjectId: existing.Project.Id}); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	resp, err := s.CreateProject(tenantContext(), req)
+	if err != nil {
+		t.Fatalf("CreateProject retried: %v", err)
+	}
+	if resp.Project.Name != "core" {
+		t.Errorf("retry created %q, want core", resp.Project.Name)
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentracing/opentracing-go"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	user "learning/grpc-project-service/api/gen/go/core/v1"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/internal/repository"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/util"
+)
+
+type MemberService interface {
+	AddMember(context.Context, *pb.AddMemberRequest) (*pb.AddMemberResponse, error)
+	RemoveMember(context.Context, *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error)
+	UpdateMemberRole(context.Context, *pb.UpdateMemberRoleRequest) (*pb.UpdateMemberRoleResponse, error)
+	ListMembers(context.Context, *pb.ListMembersRequest) (*pb.ListMembersResponse, error)
+}
+
+func (s *service) AddMember(ctx context.Context, req *pb.AddMemberRequest) (*pb.AddMemberResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::AddMember")
+	defer span.Finish()
+
+	projectID := uuid.FromStringOrNil(req.ProjectId)
+	if _, err := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(projectID))); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.userResource.GetUser(ctx, &user.GetUserRequest{UserId: req.UserId})
+	if status.Code(err) == codes.NotFound {
+		return nil, util.FieldViolation("user_id", "user doesn't exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	member := model.NewMember(projectID, req.UserId, req.Role)
+	if err := s.repository.CreateMember(ctx, member); err != nil {
+		return nil, err
+	}
+	member.UserName = resp.GetUser().GetName()
+
+	return member.ToAddMemberResponse()
+}
+
+func (s *service) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::RemoveMember")
+	defer span.Finish()
+
+	projectID := uuid.FromStringOrNil(req.ProjectId)
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(projectID))); err != nil {
+			return err
+		}
+		member, err := s.repository.GetMember(ctx, bson.M{"_id": model.MemberID(projectID, req.UserId)})
+		if err != nil {
+			return err
+		}
+		if err := s.checkNotLastOwner(ctx, member); err != nil {
+			return err
+		}
+		return s.repository.DeleteMember(ctx, bson.M{"_id": member.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RemoveMemberResponse{}, nil
+}
+
+func (s *service) UpdateMemberRole(ctx context.Context, req *pb.UpdateMemberRoleRequest) (*pb.UpdateMemberRoleResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::UpdateMemberRole")
+	defer span.Finish()
+
+	projectID := uuid.FromStringOrNil(req.ProjectId)
+	id := model.MemberID(projectID, req.UserId)
+	err := s.inTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(projectID))); err != nil {
+			return err
+		}
+		member, err := s.repository.GetMember(ctx, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if req.Role != pb.ProjectMember_OWNER {
+			if err := s.checkNotLastOwner(ctx, member); err != nil {
+				return err
+			}
+		}
+		return s.repository.UpdateMember(ctx, bson.M{"_id": id}, model.NewMemberRoleUpdate(req.Role))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repository.GetMember(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+	s.resolveMembers(ctx, member)
+
+	return member.ToUpdateMemberRoleResponse()
+}
+
+func (s *service) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::ListMembers")
+	defer span.Finish()
+
+	projectID := uuid.FromStringOrNil(req.ProjectId)
+	if _, err := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(projectID))); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repository.FindMembers(ctx, util.WithProjectID(projectID))
+	if err != nil {
+		return nil, err
+	}
+	s.resolveMembers(ctx, members...)
+
+	return model.ToListMembersResponse(members)
+}
+
+// ProjectRoles returns the membership role of the user on the project, it resolves the project roles of authz policies.
+func (s *service) ProjectRoles(ctx context.Context, projectID string, userID string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::ProjectRoles")
+	defer span.Finish()
+
+	member, err := s.repository.GetMember(ctx, bson.M{"_id": model.MemberID(uuid.FromStringOrNil(projectID), userID)})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []string{member.Role}, nil
+}
+
+// setOwner makes the authenticated caller the owner of the project it creates, the owner_id of the request can only name it.
+// Anonymous callers have to give the owner_id when authentication is enabled. Without authentication, eg: for local
+// development, a project created without one has no owner.
+func (s *service) setOwner(ctx context.Context, field string, project *model.Project) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		if len(project.OwnerID) == 0 && s.config.AuthEnabled {
+			return util.FieldViolation(field, "must be set when the caller isn't authenticated")
+		}
+		return nil
+	}
+
+	if len(project.OwnerID) > 0 && project.OwnerID != principal.Subject {
+		return util.FieldViolation(field, "must be empty or the id of the authenticated user")
+	}
+	project.OwnerID = principal.Subject
+	return nil
+}
+
+// addCreator makes the owner of a project it creates its first member, see setOwner. Projects without owner have no member.
+func (s *service) addCreator(ctx context.Context, project *model.Project) error {
+	if len(project.OwnerID) == 0 {
+		return nil
+	}
+	return s.repository.CreateMember(ctx, model.NewMember(project.ID, project.OwnerID, pb.ProjectMember_OWNER))
+}
+
+// checkNotLastOwner fails when the member is the only owner of its project, a project can't be left without one.
+func (s *service) checkNotLastOwner(ctx context.Context, member *model.Member) error {
+	if member.Role != pb.ProjectMember_OWNER.String() {
+		return nil
+	}
+
+	filter := util.WithProjectID(member.ProjectID)
+	filter["role"] = pb.ProjectMember_OWNER.String()
+	owners, err := s.repository.FindMembers(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(owners) <= 1 {
+		return util.PreconditionFailure("LAST_OWNER", "projects/"+member.ProjectID.String()+"/members/"+member.UserID,
+			"a project must keep at least one owner")
+	}
+	return nil
+}
+
+// inTransaction runs fn in a transaction when the repository supports them.
+// Otherwise fn runs on its own, concurrent changes to the same project may then interleave.
+func (s *service) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := s.repository.RunInTransaction(ctx, fn)
+	if errors.Is(err, repository.ErrTransactionsNotSupported) {
+		return fn(ctx)
+	}
+	return err
+}
+
+// resolveMembers fills the display name of the members through the UserAPI, failures are only logged.
+func (s *service) resolveMembers(ctx context.Context, members ...*model.Member) {
+	for _, member := range members {
+		resp, err := s.userResource.GetUser(ctx, &user.GetUserRequest{UserId: member.UserID})
+		if err != nil {
+			logrus.WithError(err).WithField("user_id", member.UserID).Warn("Could not resolve project member")
+		}
+		member.UserName = resp.GetUser().GetName()
+	}
+}
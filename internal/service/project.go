@@ -2,10 +2,18 @@ package service
 
 import (
 	"context"
+	"time"
+
 	"github.com/opentracing/opentracing-go"
 	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	user "learning/grpc-project-service/api/gen/go/core/v1"
 	pb "learning/grpc-project-service/api/gen/go/platform/v1"
 	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/util"
 )
 
 type ProjectService interface {
@@ -14,6 +22,7 @@ type ProjectService interface {
 	GetProject(context.Context, *pb.GetProjectRequest) (*pb.GetProjectResponse, error)
 	UpdateProject(context.Context, *pb.UpdateProjectRequest) (*pb.UpdateProjectResponse, error)
 	DeleteProject(context.Context, *pb.DeleteProjectRequest) (*pb.DeleteProjectResponse, error)
+	UndeleteProject(context.Context, *pb.UndeleteProjectRequest) (*pb.UndeleteProjectResponse, error)
 }
 
 func (s *service) CreateProject(ctx context.Context, req *pb.CreateProjectRequest) (*pb.CreateProjectResponse, error) {
@@ -24,68 +33,105 @@ func (s *service) CreateProject(ctx context.Context, req *pb.CreateProjectReques
 	if err != nil {
 		return nil, err
 	}
+	if err := s.setOwner(ctx, "owner_id", project); err != nil {
+		return nil, err
+	}
+
+	recordID := s.idempotencyRecordID(ctx, req.RequestId)
+	if len(recordID) > 0 {
+		replayed, err := s.reserveCreateProject(ctx, recordID, req)
+		if err != nil {
+			return nil, err
+		}
+		if replayed != nil {
+			// The owner name isn't recorded, it's resolved again as for every read. The creation was already published.
+			owner := &model.Project{OwnerID: replayed.GetProject().GetOwnerId()}
+			s.resolveOwners(ctx, owner)
+			replayed.Project.OwnerName = owner.OwnerName
+			return replayed, nil
+		}
+	}
+
+	// The request id is reserved, the response is recorded along with the project. Without transactions a failure after the
+	// project was created releases the request id all the same, a retry may then create another project.
+	var created *model.Project
+	err = s.inTransaction(ctx, func(ctx context.Context) (err error) {
+		// The owner is the authenticated caller, see setOwner.
+		err = s.withProjectQuota(ctx, project.OwnerID, 1, func() error {
+			return s.repository.CreateProject(ctx, project)
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.addCreator(ctx, project); err != nil {
+			return err
+		}
+
+		created, err = s.repository.GetProject(ctx, util.WithID(project.ID))
+		if err != nil || len(recordID) == 0 {
+			return err
+		}
+		resp, err := created.ToCreateProjectResponse()
+		if err != nil {
+			return err
+		}
+		update, err := model.NewIdempotencyRecordResponse(resp)
+		if err != nil {
+			return err
+		}
+		return s.repository.UpdateIdempotencyRecord(ctx, bson.M{"_id": recordID}, update)
+	})
+	if err != nil {
+		if len(recordID) > 0 {
+			s.releaseCreateProject(ctx, recordID)
+		}
+		return nil, err
+	}
+
+	s.resolveOwners(ctx, created)
 
-	//if err = s.repository.CreateProject(ctx, project); err != nil {
-	//	return nil, err
-	//}
-	//
-	//project, err = s.repository.GetProject(ctx, util.WithID(project.ID))
-	//if err != nil {
-	//	return nil, err
-	//}
-	//
-	//// TODO: call resource api, user_id can be decode from user token
-	//resp, err := s.userResource.GetUser(ctx, &user.GetUserRequest{UserId: uuid.NewV4().String()})
-	//if err != nil {
-	//	return nil, err
-	//}
-	//
-	//u := resp.GetUser()
-	//if (u != nil) {
-	//	project.UserName = u.Name
-	//}
-
-	return project.ToCreateProjectResponse()
+	resp, err := created.ToCreateProjectResponse()
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, pb.WatchProjectsResponse_CREATED, resp.Project)
+	return resp, nil
 }
 
 func (s *service) ListProjects(ctx context.Context, req *pb.ListProjectsRequest) (*pb.ListProjectsResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::ListProjects")
 	defer span.Finish()
 
-	filter := model.NewListProjectFilter(req)
-
-	//projects, count, err := s.repository.ListProjects(ctx, filter)
-	//if err != nil {
-	//	return nil, err
-	//}
-
-	projects := []*model.Project{
-		{
-			ID:   uuid.NewV4(),
-			Name: "Project 1",
-		},
+	filter, err := model.NewListProjectFilter(req, s.pageTokenKey)
+	if err != nil {
+		return nil, err
 	}
 
-	count := int64(1)
+	projects, count, err := s.repository.ListProjects(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
 
-	return model.NewPageProjects(projects, count, filter.Limit, filter.Offset).ToListProjectsAPI()
+	page, err := model.NewPageProjects(projects, count, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveOwners(ctx, page.Elements...)
 
+	return page.ToListProjectsAPI()
 }
 
 func (s *service) GetProject(ctx context.Context, req *pb.GetProjectRequest) (*pb.GetProjectResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::GetProject")
 	defer span.Finish()
 
-	//project, err := s.repository.GetProject(ctx, util.WithID(uuid.FromStringOrNil(req.ProjectId)))
-	//if err != nil {
-	//	return nil, err
-	//}
-
-	project := &model.Project{
-		ID:   uuid.FromStringOrNil(req.ProjectId),
-		Name: "Project 1",
+	project, err := s.repository.GetProject(ctx, util.WithID(uuid.FromStringOrNil(req.ProjectId)))
+	if err != nil {
+		return nil, err
 	}
 
+	s.resolveOwners(ctx, project)
+
 	return project.ToGetProjectResponse()
 }
 
@@ -93,33 +139,162 @@ func (s *service) UpdateProject(ctx context.Context, req *pb.UpdateProjectReques
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::UpdateProject")
 	defer span.Finish()
 
-	//if err := s.repository.UpdateProject(ctx,
-	//	util.WithID(uuid.FromStringOrNil(req.ProjectId)),
-	//	util.WithUpdate(bson.M{"name": req.GetBody().Name}),
-	//); err != nil {
-	//	return nil, err
-	//}
-	//
-	//project, err := s.repository.GetProject(ctx, util.WithID(uuid.FromStringOrNil(req.ProjectId)))
-	//if err != nil {
-	//	return nil, err
-	//}
+	id := uuid.FromStringOrNil(req.ProjectId)
+	etag := req.GetProject().GetEtag()
+
+	update, err := model.NewProjectUpdate(req.GetProject(), req.GetUpdateMask())
+	if err != nil {
+		return nil, err
+	}
+
+	if update != nil {
+		filter := util.WithETag(util.WithoutDeleted(util.WithID(id)), etag)
+		if err := s.repository.UpdateProject(ctx, filter, update); err != nil {
+			return nil, s.conditionalWriteError(ctx, id, etag, err)
+		}
+	}
 
-	project := &model.Project{
-		ID:   uuid.FromStringOrNil(req.ProjectId),
-		Name: "Project 1",
+	project, err := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(id)))
+	if err != nil {
+		return nil, err
 	}
+	// An empty update doesn't write anything, the etag still has to be checked.
+	if update == nil && len(etag) > 0 && etag != project.ETag {
+		return nil, etagMismatch(id)
+	}
+
+	s.resolveOwners(ctx, project)
 
-	return project.ToUpdateProjectResponse()
+	resp, err := project.ToUpdateProjectResponse()
+	if err != nil {
+		return nil, err
+	}
+	if update != nil {
+		s.publish(ctx, pb.WatchProjectsResponse_UPDATED, resp.Project)
+	}
+	return resp, nil
 }
 
 func (s *service) DeleteProject(ctx context.Context, req *pb.DeleteProjectRequest) (*pb.DeleteProjectResponse, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::DeleteProject")
 	defer span.Finish()
 
-	//if err := s.repository.DeleteProject(ctx, util.WithID(uuid.FromStringOrNil(req.ProjectId))); err != nil {
-	//	return nil, err
-	//}
+	id := uuid.FromStringOrNil(req.ProjectId)
+	if err := s.inTransaction(ctx, func(ctx context.Context) error {
+		return s.deleteProject(ctx, id, req.GetEtag())
+	}); err != nil {
+		return nil, s.conditionalWriteError(ctx, id, req.GetEtag(), err)
+	}
 
-	return new(pb.DeleteProjectResponse), nil
+	project, err := s.repository.GetProject(ctx, util.WithID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	s.resolveOwners(ctx, project)
+
+	resp, err := project.ToDeleteProjectResponse()
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, pb.WatchProjectsResponse_DELETED, resp.Project)
+	return resp, nil
+}
+
+func (s *service) UndeleteProject(ctx context.Context, req *pb.UndeleteProjectRequest) (*pb.UndeleteProjectResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::UndeleteProject")
+	defer span.Finish()
+
+	id := uuid.FromStringOrNil(req.ProjectId)
+	project, err := s.repository.GetProject(ctx, util.WithID(id))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case project.DeleteTime == nil:
+		return nil, status.Error(codes.FailedPrecondition, "project isn't deleted")
+	case project.ExpireTime != nil && project.ExpireTime.Before(time.Now()):
+		// Expired projects are about to be purged.
+		return nil, status.Error(codes.NotFound, "not found")
+	case len(req.GetEtag()) > 0 && req.GetEtag() != project.ETag:
+		return nil, etagMismatch(id)
+	}
+
+	// The etag read above guards against a concurrent delete or undelete.
+	err = s.inTransaction(ctx, func(ctx context.Context) error {
+		return s.withProjectQuota(ctx, project.OwnerID, 1, func() error {
+			return s.repository.UpdateProject(ctx, util.WithETag(util.WithID(id), project.ETag), model.NewProjectUndelete())
+		})
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.Aborted, "project was modified concurrently")
+		}
+		return nil, err
+	}
+
+	project, err = s.repository.GetProject(ctx, util.WithID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	s.resolveOwners(ctx, project)
+
+	resp, err := project.ToUndeleteProjectResponse()
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, pb.WatchProjectsResponse_UPDATED, resp.Project)
+	return resp, nil
+}
+
+// deleteProject soft deletes a project, it no longer counts against the quotas of its owner and tenant.
+func (s *service) deleteProject(ctx context.Context, id uuid.UUID, etag string) error {
+	project, err := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(id)))
+	if err != nil {
+		return err
+	}
+
+	filter := util.WithETag(util.WithoutDeleted(util.WithID(id)), etag)
+	return s.withProjectQuota(ctx, project.OwnerID, -1, func() error {
+		return s.repository.UpdateProject(ctx, filter, model.NewProjectDelete(s.config.DeleteRetention))
+	})
+}
+
+// conditionalWriteError tells a missing project apart from a stale etag once a conditional write matched nothing.
+func (s *service) conditionalWriteError(ctx context.Context, id uuid.UUID, etag string, err error) error {
+	if len(etag) == 0 || status.Code(err) != codes.NotFound {
+		return err
+	}
+	if _, getErr := s.repository.GetProject(ctx, util.WithoutDeleted(util.WithID(id))); getErr != nil {
+		return err
+	}
+	return etagMismatch(id)
+}
+
+func etagMismatch(id uuid.UUID) error {
+	return util.PreconditionFailure(util.PreconditionETag, "projects/"+id.String(),
+		"etag doesn't match the current version of the project, it was modified concurrently")
+}
+
+// resolveOwners fills the display name of the project owners through the UserAPI.
+// Failures are only logged, an unavailable user service shouldn't make projects unreadable.
+func (s *service) resolveOwners(ctx context.Context, projects ...*model.Project) {
+	names := make(map[string]string)
+	for _, project := range projects {
+		if len(project.OwnerID) == 0 {
+			continue
+		}
+
+		name, ok := names[project.OwnerID]
+		if !ok {
+			resp, err := s.userResource.GetUser(ctx, &user.GetUserRequest{UserId: project.OwnerID})
+			if err != nil {
+				logrus.WithError(err).WithField("user_id", project.OwnerID).Warn("Could not resolve project owner")
+			}
+			name = resp.GetUser().GetName()
+			names[project.OwnerID] = name
+		}
+		project.OwnerName = name
+	}
 }
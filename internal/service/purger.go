@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"learning/grpc-project-service/internal/repository"
+	"learning/grpc-project-service/pkg/provider"
+	"learning/grpc-project-service/pkg/tenant"
+)
+
+// Purger periodically removes soft deleted projects once their expire_time has passed,
+// as well as the finished webhook deliveries and GitHub delivery ids older than their retention period,
+// and the idempotency records out of their window.
+type Purger struct {
+	provider.AbstractRunProvider
+
+	config     *Config
+	repository repository.Repository
+	done       chan struct{}
+	stopped    chan struct{}
+}
+
+// NewPurger creates a Purger, it runs every config.PurgeInterval.
+func NewPurger(config *Config, repository repository.Repository) *Purger {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Purger{
+		config:     config,
+		repository: repository,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// Run purges expired projects until the Purger is closed.
+func (p *Purger) Run() error {
+	if p.config.PurgeInterval <= 0 {
+		logrus.Info("Project Purger not enabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(p.config.PurgeInterval)
+	defer ticker.Stop()
+
+	defer close(p.stopped)
+
+	p.SetRunning(true)
+	logrus.WithField("interval", p.config.PurgeInterval).Info("Project Purger launched")
+
+	for {
+		p.purge()
+
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return nil
+		}
+	}
+}
+
+func (p *Purger) purge() {
+	// Expired data of all tenants is removed at once.
+	ctx, cancel := context.WithTimeout(tenant.Unscoped(context.Background()), p.config.PurgeInterval)
+	defer cancel()
+
+	now := time.Now().UTC()
+	// A failed purge doesn't prevent the next ones, it's tried again on the next run.
+	p.run("expired projects", func() (int64, error) {
+		return p.repository.PurgeProjects(ctx, now)
+	})
+	if p.config.GitHubDeliveryRetention > 0 {
+		p.run("expired GitHub deliveries", func() (int64, error) {
+			return p.repository.PurgeGitHubDeliveries(ctx, now.Add(-p.config.GitHubDeliveryRetention))
+		})
+	}
+	if p.config.WebhookDeliveryRetention > 0 {
+		p.run("finished webhook deliveries", func() (int64, error) {
+			return p.repository.PurgeDeliveries(ctx, now.Add(-p.config.WebhookDeliveryRetention))
+		})
+	}
+	if p.config.IdempotencyWindow > 0 {
+		p.run("expired idempotency records", func() (int64, error) {
+			return p.repository.PurgeIdempotencyRecords(ctx, now.Add(-p.config.IdempotencyWindow))
+		})
+	}
+}
+
+// run runs a single purge and logs its outcome.
+func (p *Purger) run(name string, purge func() (int64, error)) {
+	count, err := purge()
+	if err != nil {
+		logrus.WithError(err).Errorf("Could not purge %s", name)
+		return
+	}
+	if count > 0 {
+		logrus.WithField("count", count).Infof("Purged %s", name)
+	}
+}
+
+// Close stops the Purger, a purge in progress is finished first.
+func (p *Purger) Close() error {
+	close(p.done)
+	if p.IsRunning() {
+		<-p.stopped
+	}
+	return p.AbstractRunProvider.Close()
+}
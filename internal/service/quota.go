@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/authz"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/util"
+)
+
+type QuotaService interface {
+	GetQuota(context.Context, *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error)
+}
+
+func (s *service) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::GetQuota")
+	defer span.Finish()
+
+	ownerID := req.OwnerId
+	if principal, ok := auth.FromContext(ctx); ok {
+		if len(ownerID) == 0 {
+			ownerID = principal.Subject
+		}
+		if ownerID != principal.Subject && !s.isQuotaAdmin(principal) {
+			return nil, status.Error(codes.PermissionDenied, "only the quota of the authenticated caller can be read")
+		}
+	}
+
+	quotas := make([]*model.Quota, 0, 2)
+	for _, quota := range s.projectQuotas(ctx, ownerID) {
+		usage, err := s.repository.GetQuotaUsage(ctx, quota.usageID)
+		switch {
+		case err == nil:
+			quota.Usage = usage.Usage
+		case status.Code(err) == codes.NotFound:
+			// Not counted yet, see incrementQuotaUsage.
+			if quota.Usage, err = s.repository.CountProjects(ctx, quota.filter); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+		quotas = append(quotas, quota.Quota)
+	}
+
+	return model.ToGetQuotaResponse(quotas)
+}
+
+// isQuotaAdmin returns whether the caller holds one of the token roles allowed to read the quota of any owner.
+func (s *service) isQuotaAdmin(principal *auth.Principal) bool {
+	for _, role := range authz.TokenRoles(principal, s.config.RolesClaim) {
+		for _, admin := range s.config.QuotaAdminRoles {
+			if role == admin {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// projectQuota is a quota along with the id of its usage and the filter matching the projects counting against it.
+type projectQuota struct {
+	*model.Quota
+	usageID string
+	filter  bson.M
+}
+
+// projectQuotas returns the quotas a project of ownerID counts against, without their usage.
+// The tenant quota is only returned when the request has a tenant.
+func (s *service) projectQuotas(ctx context.Context, ownerID string) []projectQuota {
+	tenantID := tenant.FromContext(ctx)
+	limits := s.quotaLimits(tenantID)
+
+	quotas := make([]projectQuota, 0, 2)
+	if len(tenantID) > 0 {
+		subject := model.TenantQuotaSubject(tenantID)
+		quotas = append(quotas, projectQuota{
+			Quota:   &model.Quota{Name: model.QuotaProjectsPerTenant, Subject: subject, Limit: *limits.ProjectsPerTenant},
+			usageID: model.QuotaUsageID(tenantID, model.QuotaProjectsPerTenant, subject),
+			filter:  util.WithoutDeleted(bson.M{}),
+		})
+	}
+	if len(ownerID) > 0 {
+		subject := model.OwnerQuotaSubject(ownerID)
+		quotas = append(quotas, projectQuota{
+			Quota:   &model.Quota{Name: model.QuotaProjectsPerOwner, Subject: subject, Limit: *limits.ProjectsPerOwner},
+			usageID: model.QuotaUsageID(tenantID, model.QuotaProjectsPerOwner, subject),
+			filter:  util.WithoutDeleted(bson.M{"ownerId": ownerID}),
+		})
+	}
+	return quotas
+}
+
+// withProjectQuota counts delta more projects of ownerID against its quotas and the tenant one,
+// then runs fn writing them. A negative delta releases projects, when they are deleted.
+// Fails with ResourceExhausted and a google.rpc.QuotaFailure detail when a quota would be exceeded, fn isn't run then.
+// Soft deleted projects don't count, they're counted again when undeleted.
+//
+// The usages are updated before fn, conditionally, so concurrent requests can't exceed a limit together. They are
+// reverted when fn fails, which a transaction already does.
+func (s *service) withProjectQuota(ctx context.Context, ownerID string, delta int64, fn func() error) error {
+	quotas := s.projectQuotas(ctx, ownerID)
+	for i, quota := range quotas {
+		ok, err := s.incrementQuotaUsage(ctx, quota, delta)
+		if err == nil && !ok {
+			err = util.QuotaFailure(quota.Subject, quota.Description())
+		}
+		if err != nil {
+			s.revertQuotaUsages(ctx, quotas[:i], delta)
+			return err
+		}
+	}
+
+	if err := fn(); err != nil {
+		s.revertQuotaUsages(ctx, quotas, delta)
+		return err
+	}
+	return nil
+}
+
+// incrementQuotaUsage adds delta to the usage of a quota unless it would exceed its limit, returns whether it was added.
+// A missing usage, eg: the quota was just introduced, starts from the projects counting against it.
+func (s *service) incrementQuotaUsage(ctx context.Context, quota projectQuota, delta int64) (bool, error) {
+	ok, err := s.repository.IncrementQuotaUsage(ctx, quota.usageID, delta, quota.Limit)
+	if status.Code(err) != codes.NotFound {
+		return ok, err
+	}
+
+	usage, err := s.repository.CountProjects(ctx, quota.filter)
+	if err != nil {
+		return false, err
+	}
+	// A concurrent request may have created it first, it is then kept.
+	if err := s.repository.InitQuotaUsage(ctx, model.NewQuotaUsage(quota.usageID, usage)); err != nil {
+		return false, err
+	}
+	return s.repository.IncrementQuotaUsage(ctx, quota.usageID, delta, quota.Limit)
+}
+
+// revertQuotaUsages undoes the increments of withProjectQuota, failures are only logged.
+func (s *service) revertQuotaUsages(ctx context.Context, quotas []projectQuota, delta int64) {
+	for _, quota := range quotas {
+		if _, err := s.repository.IncrementQuotaUsage(ctx, quota.usageID, -delta, 0); err != nil {
+			logrus.WithError(err).WithField("quota", quota.usageID).Error("Could not revert quota usage")
+		}
+	}
+}
+
+// quotaLimits returns the limits of a tenant, its overrides applied over the defaults.
+func (s *service) quotaLimits(tenantID string) model.QuotaLimits {
+	limits := model.QuotaLimits{
+		ProjectsPerOwner:  &s.config.QuotaProjectsPerOwner,
+		ProjectsPerTenant: &s.config.QuotaProjectsPerTenant,
+	}
+	if override, ok := s.quotaOverrides.Tenants[tenantID]; ok {
+		if override.ProjectsPerOwner != nil {
+			limits.ProjectsPerOwner = override.ProjectsPerOwner
+		}
+		if override.ProjectsPerTenant != nil {
+			limits.ProjectsPerTenant = override.ProjectsPerTenant
+		}
+	}
+	return limits
+}
+
+// loadQuotaOverrides reads and validates the YAML quota overrides, unknown fields are rejected so a typo isn't ignored.
+func loadQuotaOverrides(path string) (*model.QuotaOverrides, error) {
+	overrides := new(model.QuotaOverrides)
+	if len(path) == 0 {
+		return overrides, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(overrides); err != nil {
+		return nil, fmt.Errorf("invalid quota overrides %s: %w", path, err)
+	}
+
+	for tenantID, limits := range overrides.Tenants {
+		if limits == nil {
+			return nil, fmt.Errorf("invalid quota overrides %s: tenants.%s can't be empty", path, tenantID)
+		}
+		if (limits.ProjectsPerOwner != nil && *limits.ProjectsPerOwner < 0) || (limits.ProjectsPerTenant != nil && *limits.ProjectsPerTenant < 0) {
+			return nil, fmt.Errorf("invalid quota overrides %s: limits of tenants.%s can't be negative", path, tenantID)
+		}
+	}
+	return overrides, nil
+}
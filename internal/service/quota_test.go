@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+)
+
+const (
+	testOwner      = "7d1b0c8e-0000-4000-8000-00000000000a"
+	testOtherOwner = "7d1b0c8e-0000-4000-8000-00000000000b"
+)
+
+// newTestQuotaService allows 2 projects per owner and 3 per tenant, deleted projects can be restored for an hour.
+func newTestQuotaService(t *testing.T) *service {
+	config := newTestConfig()
+	config.QuotaProjectsPerOwner = 2
+	config.QuotaProjectsPerTenant = 3
+	config.DeleteRetention = time.Hour
+	return newTestService(t, config)
+}
+
+// quotaUsages returns the usage of the tenant quota and of the owner quota.
+func quotaUsages(t *testing.T, s *service, ownerID string) (int64, int64) {
+	t.Helper()
+	resp, err := s.GetQuota(tenantContext(), &pb.GetQuotaRequest{OwnerId: ownerID})
+	if err != nil {
+		t.Fatalf("GetQuota: %v", err)
+	}
+	if len(resp.Quotas) != 2 {
+		t.Fatalf("GetQuota returned %d quotas, want 2", len(resp.Quotas))
+	}
+	return resp.Quotas[0].Usage, resp.Quotas[1].Usage
+}
+
+func createOwnedProject(s *service, ownerID string) (*pb.Project, error) {
+	resp, err := s.CreateProject(tenantContext(), &pb.CreateProjectRequest{Name: "infra", OwnerId: ownerID})
+	return resp.GetProject(), err
+}
+
+// checkQuotaFailure checks err is ResourceExhausted with a QuotaFailure detail about subject.
+func checkQuotaFailure(t *testing.T, err error, subject string) {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("error = %v, want ResourceExhausted", err)
+	}
+	for _, detail := range st.Details() {
+		if failure, ok := detail.(*errdetails.QuotaFailure); ok && len(failure.Violations) == 1 && failure.Violations[0].Subject == subject {
+			return
+		}
+	}
+	t.Errorf("error details = %v, want a QuotaFailure of %s", st.Details(), subject)
+}
+
+func TestProjectQuotaLimits(t *testing.T) {
+	s := newTestQuotaService(t)
+
+	for i := 0; i < 2; i++ {
+		if _, err := createOwnedProject(s, testOwner); err != nil {
+			t.Fatalf("CreateProject %d: %v", i, err)
+		}
+	}
+	_, err := createOwnedProject(s, testOwner)
+	checkQuotaFailure(t, err, model.OwnerQuotaSubject(testOwner))
+	// The tenant usage, incremented before the owner one failed, is reverted.
+	if tenantUsage, ownerUsage := quotaUsages(t, s, testOwner); tenantUsage != 2 || ownerUsage != 2 {
+		t.Errorf("usages = %d/%d after a refused project, want 2/2", tenantUsage, ownerUsage)
+	}
+
+	if _, err := createOwnedProject(s, testOtherOwner); err != nil {
+		t.Fatalf("CreateProject for another owner: %v", err)
+	}
+	_, err = createOwnedProject(s, testOtherOwner)
+	checkQuotaFailure(t, err, model.TenantQuotaSubject(testTenant))
+	if tenantUsage, ownerUsage := quotaUsages(t, s, testOtherOwner); tenantUsage != 3 || ownerUsage != 1 {
+		t.Errorf("usages = %d/%d once the tenant is full, want 3/1", tenantUsage, ownerUsage)
+	}
+
+	// The limits of a tenant don't apply to another one.
+	other := context.Background()
+	if _, err := s.CreateProject(other, &pb.CreateProjectRequest{Name: "infra", OwnerId: testOwner}); err != nil {
+		t.Errorf("CreateProject without tenant: %v", err)
+	}
+}
+
+func TestProjectQuotaStartsFromExistingProjects(t *testing.T) {
+	s := newTestQuotaService(t)
+
+	// Created before the quota was counted, eg: before the quota was introduced.
+	project, _ := model.NewProject(&pb.CreateProjectRequest{Name: "infra", OwnerId: testOwner})
+	if err := s.repository.CreateProject(tenantContext(), project); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+
+	if _, err := createOwnedProject(s, testOwner); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	_, err := createOwnedProject(s, testOwner)
+	checkQuotaFailure(t, err, model.OwnerQuotaSubject(testOwner))
+}
+
+func TestProjectQuotaRevertedOnFailure(t *testing.T) {
+	s := newTestQuotaService(t)
+	if _, err := createOwnedProject(s, testOwner); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+
+	failure := errors.New("write failed")
+	err := s.withProjectQuota(tenantContext(), testOwner, 1, func() error { return failure })
+	if !errors.Is(err, failure) {
+		t.Fatalf("withProjectQuota: %v, want the error of fn", err)
+	}
+	if tenantUsage, ownerUsage := quotaUsages(t, s, testOwner); tenantUsage != 1 || ownerUsage != 1 {
+		t.Errorf("usages = %d/%d after a failed write, want 1/1", tenantUsage, ownerUsage)
+	}
+
+	// fn isn't run when a quota would be exceeded.
+	if _, err := createOwnedProject(s, testOwner); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	err = s.withProjectQuota(tenantContext(), testOwner, 1, func() error {
+		t.Error("fn run beyond the quota")
+		return nil
+	})
+	checkQuotaFailure(t, err, model.OwnerQuotaSubject(testOwner))
+}
+
+func TestProjectQuotaSoftDelete(t *testing.T) {
+	s := newTestQuotaService(t)
+
+	var projects []*pb.Project
+	for i := 0; i < 2; i++ {
+		project, err := createOwnedProject(s, testOwner)
+		if err != nil {
+			t.Fatalf("CreateProject %d: %v", i, err)
+		}
+		projects = append(projects, project)
+	}
+
+	// A soft deleted project releases its quota.
+	if _, err := s.DeleteProject(tenantContext(), &pb.DeleteProjectRequest{ProjectId: projects[0].Id}); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	if tenantUsage, ownerUsage := quotaUsages(t, s, testOwner); tenantUsage != 1 || ownerUsage != 1 {
+		t.Errorf("usages = %d/%d after a delete, want 1/1", tenantUsage, ownerUsage)
+	}
+	if _, err := createOwnedProject(s, testOwner); err != nil {
+		t.Fatalf("CreateProject after a delete: %v", err)
+	}
+
+	// It counts again when undeleted, which the quota may then refuse.
+	_, err := s.UndeleteProject(tenantContext(), &pb.UndeleteProjectRequest{ProjectId: projects[0].Id})
+	checkQuotaFailure(t, err, model.OwnerQuotaSubject(testOwner))
+
+	if _, err := s.DeleteProject(tenantContext(), &pb.DeleteProjectRequest{ProjectId: projects[1].Id}); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	if _, err := s.UndeleteProject(tenantContext(), &pb.UndeleteProjectRequest{ProjectId: projects[0].Id}); err != nil {
+		t.Fatalf("UndeleteProject: %v", err)
+	}
+	if tenantUsage, ownerUsage := quotaUsages(t, s, testOwner); tenantUsage != 2 || ownerUsage != 2 {
+		t.Errorf("usages = %d/%d after an undelete, want 2/2", tenantUsage, ownerUsage)
+	}
+}
@@ -1,7 +1,14 @@
 package service
 
 import (
+	"crypto/rand"
+
+	"github.com/sirupsen/logrus"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/internal/repository"
+	"learning/grpc-project-service/pkg/authz"
 	"learning/grpc-project-service/pkg/provider"
+	"learning/grpc-project-service/pkg/provider/broadcast"
 
 	"learning/grpc-project-service/pkg/resource/user"
 )
@@ -10,23 +17,58 @@ type (
 	Service interface {
 		provider.Provider
 		ProjectService
+		BatchService
+		WatchService
+		WebhookService
+		GitHubService
+		MemberService
+		ApiKeyService
+		QuotaService
+		authz.RoleResolver
 	}
 
 	service struct {
 		provider.AbstractProvider
-		//repository   repository.Repository
+		config       *Config
+		repository   repository.Repository
 		userResource user.UserResource
+		broadcaster  *broadcast.Broadcaster
+		pageTokenKey []byte
+
+		quotaOverrides *model.QuotaOverrides
 	}
 )
 
-func New() Service {
+// New creates the Service, changes to projects are published on the broadcaster.
+func New(config *Config, repository repository.Repository, broadcaster *broadcast.Broadcaster) Service {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
 	return &service{
-		//repository:   repository,
+		config:       config,
+		repository:   repository,
+		broadcaster:  broadcaster,
 		userResource: user.New(user.NewConfigFromEnv()),
 	}
 }
 
 func (s *service) Init() error {
+	s.pageTokenKey = []byte(s.config.PageTokenSecret)
+	if len(s.pageTokenKey) == 0 {
+		logrus.Warn("PAGE_TOKEN_SECRET not set, page tokens won't be valid across restarts or replicas")
+		s.pageTokenKey = make([]byte, 32)
+		if _, err := rand.Read(s.pageTokenKey); err != nil {
+			return err
+		}
+	}
+
+	overrides, err := loadQuotaOverrides(s.config.QuotaOverrides)
+	if err != nil {
+		return err
+	}
+	s.quotaOverrides = overrides
+
 	if err := s.userResource.Init(); err != nil {
 		//logging.WithError(err).Errorf("Failed to init user resource")
 		return err
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/internal/repository"
+	"learning/grpc-project-service/pkg/provider/broadcast"
+	"learning/grpc-project-service/pkg/resource/user"
+)
+
+// newTestService returns a service backed by the memory repository, resolving users with the mock UserAPI.
+func newTestService(t *testing.T, config *Config) *service {
+	t.Helper()
+	broadcaster := broadcast.New(&broadcast.Config{HistorySize: 8, SubscriberBuffer: 8})
+	if err := broadcaster.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() { _ = broadcaster.Close() })
+
+	return &service{
+		config:         config,
+		repository:     repository.NewMemory(),
+		broadcaster:    broadcaster,
+		userResource:   user.New(&user.Config{MockEnabled: true}),
+		pageTokenKey:   []byte("0123456789abcdef0123456789abcdef"),
+		quotaOverrides: new(model.QuotaOverrides),
+	}
+}
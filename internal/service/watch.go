@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/provider/broadcast"
+	"learning/grpc-project-service/pkg/tenant"
+)
+
+type WatchService interface {
+	WatchProjects(*pb.WatchProjectsRequest, pb.ProjectAPI_WatchProjectsServer) error
+}
+
+// projectEvent payload published on the Broadcaster after each change of a project.
+type projectEvent struct {
+	eventType pb.WatchProjectsResponse_EventType
+	project   *pb.Project
+	tenantID  string // Tenant of the project, only its watchers and webhooks are notified.
+	time      time.Time
+}
+
+// publish notifies the watchers of a change, it never blocks.
+func (s *service) publish(ctx context.Context, eventType pb.WatchProjectsResponse_EventType, projects ...*pb.Project) {
+	now := time.Now().UTC()
+	tenantID := tenant.FromContext(ctx)
+	for _, project := range projects {
+		s.broadcaster.Publish(tenantID, &projectEvent{eventType: eventType, project: project, tenantID: tenantID, time: now})
+	}
+}
+
+func (s *service) WatchProjects(req *pb.WatchProjectsRequest, stream pb.ProjectAPI_WatchProjectsServer) error {
+	ctx := stream.Context()
+	// Watchers only receive the events of their tenant, with sequence tokens of their tenant.
+	sub, err := s.broadcaster.Subscribe(tenant.FromContext(ctx), req.SequenceToken)
+	switch {
+	case errors.Is(err, broadcast.ErrInvalidToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, broadcast.ErrTokenExpired):
+		return status.Error(codes.OutOfRange, err.Error())
+	case err != nil:
+		return watchError(err)
+	}
+	defer sub.Close()
+
+	// Sending the headers right away lets clients, such as the gateway, know the stream is established before the first event.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return watchError(sub.Err())
+			}
+			payload, ok := event.Payload.(*projectEvent)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(&pb.WatchProjectsResponse{
+				Type:          payload.eventType,
+				Project:       payload.project,
+				SequenceToken: event.Token,
+				EventTime:     timestamppb.New(payload.time),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func watchError(err error) error {
+	switch {
+	case errors.Is(err, broadcast.ErrSlowConsumer):
+		return status.Error(codes.ResourceExhausted, "watcher fell behind, resume from the last sequence_token received")
+	case errors.Is(err, broadcast.ErrClosed):
+		return status.Error(codes.Unavailable, "server is shutting down, resume from the last sequence_token received")
+	}
+	return err
+}
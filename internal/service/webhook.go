@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/opentracing/opentracing-go"
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/internal/model"
+	"learning/grpc-project-service/pkg/util"
+)
+
+type WebhookService interface {
+	CreateWebhook(context.Context, *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error)
+	GetWebhook(context.Context, *pb.GetWebhookRequest) (*pb.GetWebhookResponse, error)
+	ListWebhooks(context.Context, *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error)
+	DeleteWebhook(context.Context, *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error)
+	ListDeliveries(context.Context, *pb.ListDeliveriesRequest) (*pb.ListDeliveriesResponse, error)
+}
+
+func (s *service) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::CreateWebhook")
+	defer span.Finish()
+
+	if err := s.checkWebhookTarget(ctx, req.Url); err != nil {
+		return nil, err
+	}
+
+	webhook := model.NewWebhook(req)
+	if err := s.repository.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook.ToCreateWebhookResponse()
+}
+
+// checkWebhookTarget rejects the URLs of hosts that aren't public, unless config.WebhookAllowPrivateTargets is set.
+// Their addresses may change, the Dispatcher checks them again when it connects.
+func (s *service) checkWebhookTarget(ctx context.Context, rawURL string) error {
+	if s.config.WebhookAllowPrivateTargets {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return util.FieldViolation("url", err.Error())
+	}
+	err = util.CheckPublicHost(ctx, u.Hostname())
+	if errors.Is(err, util.ErrPrivateAddress) {
+		return util.FieldViolation("url", "must not target a private, loopback or link-local address")
+	}
+	if err != nil {
+		return util.FieldViolation("url", fmt.Sprintf("host %s could not be resolved", u.Hostname()))
+	}
+	return nil
+}
+
+func (s *service) GetWebhook(ctx context.Context, req *pb.GetWebhookRequest) (*pb.GetWebhookResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::GetWebhook")
+	defer span.Finish()
+
+	webhook, err := s.repository.GetWebhook(ctx, util.WithID(uuid.FromStringOrNil(req.WebhookId)))
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook.ToGetWebhookResponse()
+}
+
+func (s *service) ListWebhooks(ctx context.Context, _ *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::ListWebhooks")
+	defer span.Finish()
+
+	webhooks, err := s.repository.FindWebhooks(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ToListWebhooksResponse(webhooks)
+}
+
+func (s *service) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::DeleteWebhook")
+	defer span.Finish()
+
+	id := uuid.FromStringOrNil(req.WebhookId)
+	if err := s.repository.DeleteWebhook(ctx, util.WithID(id)); err != nil {
+		return nil, err
+	}
+	// Deliveries left behind by a failure here are dropped by the Dispatcher once it finds their webhook gone.
+	if err := s.repository.DeleteDeliveries(ctx, bson.M{"webhookId": id}); err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteWebhookResponse{}, nil
+}
+
+func (s *service) ListDeliveries(ctx context.Context, req *pb.ListDeliveriesRequest) (*pb.ListDeliveriesResponse, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service::ListDeliveries")
+	defer span.Finish()
+
+	filter := model.NewListDeliveriesFilter(req)
+	if filter.WebhookID != nil {
+		if _, err := s.repository.GetWebhook(ctx, util.WithID(*filter.WebhookID)); err != nil {
+			return nil, err
+		}
+	}
+
+	deliveries, count, err := s.repository.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ToListDeliveriesResponse(deliveries, count, filter)
+}
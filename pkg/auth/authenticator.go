@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/pkg/provider"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// Authenticator validates the bearer JWTs of incoming gRPC requests.
+// Tokens must be signed with RS256 or ES256 by a key of the configured JWKS.
+type Authenticator struct {
+	provider.AbstractProvider
+
+	Config  *Config
+	ApiKeys ApiKeyVerifier // Verifies the x-api-key metadata, API keys are rejected when nil.
+	keys    *keySet
+	parser  *jwt.Parser
+}
+
+// ErrInvalidApiKey is returned by an ApiKeyVerifier for unknown, revoked or expired keys.
+var ErrInvalidApiKey = errors.New("invalid api key")
+
+// ApiKeyVerifier resolves the API keys sent by callers that can't obtain a bearer token.
+type ApiKeyVerifier interface {
+	// VerifyApiKey returns the Principal authenticated by the key, or ErrInvalidApiKey.
+	VerifyApiKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// NewAuthenticator creates an Authenticator, it is unusable until initialized.
+func NewAuthenticator(config *Config) *Authenticator {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Authenticator{Config: config}
+}
+
+// Init loads the JWKS, a misconfigured key source fails at startup instead of rejecting every request.
+func (a *Authenticator) Init() error {
+	if !a.Config.Enabled {
+		return nil
+	}
+	if len(a.Config.JWKS) == 0 {
+		return errors.New("AUTH_JWKS must be set when authentication is enabled")
+	}
+
+	a.keys = newKeySet(a.Config.JWKS, a.Config.JWKSRefreshInterval)
+	if err := a.keys.load(context.Background()); err != nil {
+		return fmt.Errorf("could not load JWKS %s: %w", a.Config.JWKS, err)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(a.Config.Leeway),
+	}
+	if len(a.Config.Issuer) > 0 {
+		opts = append(opts, jwt.WithIssuer(a.Config.Issuer))
+	}
+	if len(a.Config.Audience) > 0 {
+		opts = append(opts, jwt.WithAudience(a.Config.Audience))
+	}
+	a.parser = jwt.NewParser(opts...)
+	return nil
+}
+
+// AuthFunc authenticates the request with its x-api-key metadata, or else the token of its authorization metadata,
+// and adds its Principal to the context.
+// It has the signature of grpc_auth.AuthFunc, requests to unauthenticated methods go through without a Principal.
+func (a *Authenticator) AuthFunc(ctx context.Context) (context.Context, error) {
+	if !a.Config.Enabled {
+		return ctx, nil
+	}
+	if method, ok := grpc.Method(ctx); ok && util.MatchAnyMethod(a.Config.UnauthenticatedMethods, method) {
+		return ctx, nil
+	}
+
+	var principal *Principal
+	if key := metadata.ValueFromIncomingContext(ctx, "x-api-key"); len(key) > 0 {
+		p, err := a.authenticateApiKey(ctx, key[0])
+		if err != nil {
+			return nil, err
+		}
+		principal = p
+	} else {
+		token, err := bearerToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if principal, err = a.Authenticate(ctx, token); err != nil {
+			return nil, err
+		}
+	}
+
+	grpc_ctxtags.Extract(ctx).Set("auth.sub", principal.Subject)
+	return NewContext(ctx, principal), nil
+}
+
+// Authenticate validates the token and returns the Principal it describes.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, invalidToken(tokenErrorDescription(err))
+	}
+
+	principal := &Principal{Claims: claims}
+	principal.Subject, _ = claims.GetSubject()
+	principal.Issuer, _ = claims.GetIssuer()
+	principal.Audience, _ = claims.GetAudience()
+	principal.Tenant, _ = claims[a.Config.TenantClaim].(string)
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		principal.ExpireTime = exp.Time.UTC()
+	}
+	switch scope := claims["scope"].(type) {
+	case string:
+		principal.Scopes = strings.Fields(scope)
+	default:
+		if scp, ok := claims["scp"].([]interface{}); ok {
+			for _, s := range scp {
+				if s, ok := s.(string); ok {
+					principal.Scopes = append(principal.Scopes, s)
+				}
+			}
+		}
+	}
+	if len(principal.Subject) == 0 {
+		return nil, invalidToken("token has no subject")
+	}
+	return principal, nil
+}
+
+func (a *Authenticator) authenticateApiKey(ctx context.Context, key string) (*Principal, error) {
+	if a.ApiKeys == nil {
+		return nil, status.Error(codes.Unauthenticated, `ApiKey error="invalid_request", error_description="api keys aren't accepted"`)
+	}
+
+	principal, err := a.ApiKeys.VerifyApiKey(ctx, key)
+	if errors.Is(err, ErrInvalidApiKey) {
+		return nil, status.Error(codes.Unauthenticated, `ApiKey error="invalid_key", error_description="api key is unknown, revoked or expired"`)
+	}
+	return principal, err
+}
+
+// tokenErrorDescription tells the caller why its token was rejected, without details about the keys.
+func tokenErrorDescription(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "token is expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "token is not valid yet"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return "token has an invalid issuer"
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return "token has an invalid audience"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return "token has no expiration time"
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "token is malformed"
+	default:
+		return "token signature is invalid"
+	}
+}
+
+// bearerToken returns the token of the authorization metadata.
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		// No error code when no credentials were sent, see RFC 6750 section 3.1.
+		return "", status.Error(codes.Unauthenticated, "Bearer")
+	}
+
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || len(strings.TrimSpace(token)) == 0 {
+		return "", status.Error(codes.Unauthenticated, `Bearer error="invalid_request", error_description="authorization must be a bearer token"`)
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// invalidToken returns an Unauthenticated error whose message is a WWW-Authenticate challenge, the gateway sends it as is.
+func invalidToken(description string) error {
+	return status.Errorf(codes.Unauthenticated, `Bearer error="invalid_token", error_description=%q`, description)
+}
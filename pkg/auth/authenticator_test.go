@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testIssuer   = "https://issuer.test"
+	testAudience = "projects"
+)
+
+// testKeys are the keys of the JWKS of newTestAuthenticator.
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestAuthenticator(t *testing.T) (*Authenticator, testKeys) {
+	t.Helper()
+	keys := testKeys{rsa: newRSAKey(t), ec: newECKey(t)}
+	server := newJWKSServer(t, rsaJWK("rsa", keys.rsa), ecJWK("ec", keys.ec))
+
+	a := NewAuthenticator(&Config{
+		Enabled:                true,
+		JWKS:                   server.URL,
+		JWKSRefreshInterval:    time.Hour,
+		Issuer:                 testIssuer,
+		Audience:               testAudience,
+		Leeway:                 30 * time.Second,
+		TenantClaim:            "tenant_id",
+		UnauthenticatedMethods: []string{"/grpc.health.v1.Health/", "/platform.v1.GitHubAPI/ReceiveGitHubEvent"},
+	})
+	if err := a.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	return a, keys
+}
+
+// validClaims returns the claims of a token accepted by newTestAuthenticator.
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":       "user-1",
+		"iss":       testIssuer,
+		"aud":       testAudience,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": "acme",
+		"scope":     "projects.read projects.write",
+	}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if len(kid) > 0 {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return signed
+}
+
+func TestAuthenticate(t *testing.T) {
+	a, keys := newTestAuthenticator(t)
+	with := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := validClaims()
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+		}
+		return claims
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string // Part of the error description, empty when the token is accepted.
+	}{
+		{name: "RS256", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims())},
+		{name: "ES256", token: signToken(t, jwt.SigningMethodES256, "ec", keys.ec, validClaims())},
+		{name: "expired within the leeway", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()}))},
+		{name: "audience among others", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"aud": []string{"billing", testAudience}}))},
+		{name: "expired", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()})), wantErr: "token is expired"},
+		{name: "not valid yet", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()})), wantErr: "token is not valid yet"},
+		{name: "without expiration", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"exp": nil})), wantErr: "token has no expiration time"},
+		{name: "other issuer", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"iss": "https://other.test"})), wantErr: "token has an invalid issuer"},
+		{name: "other audience", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"aud": "billing"})), wantErr: "token has an invalid audience"},
+		{name: "without subject", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, with(jwt.MapClaims{"sub": nil})), wantErr: "token has no subject"},
+		{name: "unknown key", token: signToken(t, jwt.SigningMethodRS256, "other", keys.rsa, validClaims()), wantErr: "token signature is invalid"},
+		{name: "signed by another key", token: signToken(t, jwt.SigningMethodRS256, "rsa", newRSAKey(t), validClaims()), wantErr: "token signature is invalid"},
+		{name: "HS256", token: signToken(t, jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims()), wantErr: "token signature is invalid"},
+		{name: "malformed", token: "not.a.token", wantErr: "token is malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := a.Authenticate(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				if status.Code(err) != codes.Unauthenticated || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Authenticate() error = %v, want Unauthenticated with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.Subject != "user-1" || principal.Issuer != testIssuer || principal.Tenant != "acme" {
+				t.Errorf("Authenticate() = %+v", principal)
+			}
+			if !principal.HasScope("projects.read") || !principal.HasScope("projects.write") {
+				t.Errorf("scopes = %v, want projects.read and projects.write", principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestAuthenticateScopes(t *testing.T) {
+	a, keys := newTestAuthenticator(t)
+
+	tests := []struct {
+		name  string
+		scope interface{}
+		scp   interface{}
+		want  []string
+	}{
+		{name: "scope claim", scope: "a b", want: []string{"a", "b"}},
+		{name: "scp list", scp: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "scope wins over scp", scope: "a", scp: []string{"b"}, want: []string{"a"}},
+		{name: "none", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			delete(claims, "scope")
+			if tt.scope != nil {
+				claims["scope"] = tt.scope
+			}
+			if tt.scp != nil {
+				claims["scp"] = tt.scp
+			}
+			principal, err := a.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, claims))
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if strings.Join(principal.Scopes, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("scopes = %v, want %v", principal.Scopes, tt.want)
+			}
+		})
+	}
+}
+
+// methodStream makes grpc.Method return the method of a test request.
+type methodStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s methodStream) Method() string { return s.method }
+
+// requestContext returns the context of a request to method with the given metadata.
+func requestContext(method string, kv ...string) context.Context {
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), methodStream{method: method})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+}
+
+// apiKeys accepts the key "valid".
+type apiKeys struct{}
+
+func (apiKeys) VerifyApiKey(_ context.Context, key string) (*Principal, error) {
+	if key != "valid" {
+		return nil, ErrInvalidApiKey
+	}
+	return &Principal{Subject: "key-owner", ApiKeyID: "key-1"}, nil
+}
+
+func TestAuthFunc(t *testing.T) {
+	a, keys := newTestAuthenticator(t)
+	token := signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims())
+	const method = "/platform.v1.ProjectAPI/GetProject"
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		apiKeys     ApiKeyVerifier
+		wantSubject string // Empty when the request goes through without a Principal.
+		wantErr     string
+	}{
+		{name: "bearer token", ctx: requestContext(method, "authorization", "Bearer "+token), wantSubject: "user-1"},
+		{name: "lowercase scheme", ctx: requestContext(method, "authorization", "bearer "+token), wantSubject: "user-1"},
+		{name: "no credentials", ctx: requestContext(method), wantErr: "Bearer"},
+		{name: "other scheme", ctx: requestContext(method, "authorization", "Basic dXNlcjpwYXNz"), wantErr: "authorization must be a bearer token"},
+		{name: "empty token", ctx: requestContext(method, "authorization", "Bearer "), wantErr: "authorization must be a bearer token"},
+		{name: "invalid token", ctx: requestContext(method, "authorization", "Bearer not.a.token"), wantErr: "token is malformed"},
+		{name: "api key", ctx: requestContext(method, "x-api-key", "valid"), apiKeys: apiKeys{}, wantSubject: "key-owner"},
+		{name: "api key wins over the token", ctx: requestContext(method, "x-api-key", "revoked", "authorization", "Bearer "+token), apiKeys: apiKeys{}, wantErr: "api key is unknown, revoked or expired"},
+		{name: "api keys not accepted", ctx: requestContext(method, "x-api-key", "valid"), wantErr: "api keys aren't accepted"},
+		{name: "unauthenticated method", ctx: requestContext("/grpc.health.v1.Health/Check")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a.ApiKeys = tt.apiKeys
+			ctx, err := a.AuthFunc(tt.ctx)
+			if tt.wantErr != "" {
+				if status.Code(err) != codes.Unauthenticated || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("AuthFunc() error = %v, want Unauthenticated with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthFunc() error = %v", err)
+			}
+			principal, ok := FromContext(ctx)
+			if tt.wantSubject == "" {
+				if ok {
+					t.Errorf("AuthFunc() authenticated %+v, want no principal", principal)
+				}
+				return
+			}
+			if !ok || principal.Subject != tt.wantSubject {
+				t.Errorf("AuthFunc() principal = %+v, want subject %s", principal, tt.wantSubject)
+			}
+		})
+	}
+
+	disabled := NewAuthenticator(&Config{})
+	if _, err := disabled.AuthFunc(requestContext(method)); err != nil {
+		t.Errorf("AuthFunc() with authentication disabled: %v", err)
+	}
+}
+
+func TestUnauthenticatedMethods(t *testing.T) {
+	a, _ := newTestAuthenticator(t)
+
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{method: "/grpc.health.v1.Health/Check", want: true},
+		{method: "/grpc.health.v1.Health/Watch", want: true},
+		{method: "/platform.v1.GitHubAPI/ReceiveGitHubEvent", want: true},
+		{method: "/grpc.health.v1.HealthCheck/Check", want: false},
+		{method: "/platform.v1.GitHubAPI/RotateGitHubWebhookSecret", want: false},
+		{method: "/platform.v1.GitHubAPI/ReceiveGitHubEventAgain", want: false},
+		{method: "/platform.v1.ProjectAPI/GetProject", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			// Without credentials, only the unauthenticated methods go through.
+			_, err := a.AuthFunc(requestContext(tt.method))
+			if got := err == nil; got != tt.want {
+				t.Errorf("AuthFunc() error = %v, want unauthenticated %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultJWKSRefreshInterval = 15 * time.Minute
+	defaultLeeway              = 30 * time.Second
+	// Health checks and reflection are used by probes and tooling that don't hold tokens.
+	defaultUnauthenticatedMethods = "/grpc.health.v1.Health/,/grpc.reflection.v1.ServerReflection/,/grpc.reflection.v1alpha.ServerReflection/"
+)
+
+// Config configuration of the JWT bearer Authenticator.
+type Config struct {
+	Enabled             bool          // Whether or not requests need a bearer token.
+	JWKS                string        // Path or http(s) URL of the JSON Web Key Set verifying the tokens.
+	JWKSRefreshInterval time.Duration // How long the keys are cached before being loaded again.
+	Issuer              string        // Expected iss claim, not checked when empty.
+	Audience            string        // Expected aud claim, not checked when empty.
+	Leeway              time.Duration // Clock skew tolerated on the exp and nbf claims.
+	TenantClaim         string        // Claim holding the tenant of the caller.
+
+	// Full gRPC method names that don't need a token, a name ending with / matches all methods of the service, see util.MatchMethod.
+	UnauthenticatedMethods []string
+}
+
+// NewConfigFromEnv initializes the configuration from environment variables or config file.
+func NewConfigFromEnv() *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	v.SetDefault("AUTH_ENABLED", false)
+	v.SetDefault("AUTH_JWKS", "")
+	v.SetDefault("AUTH_JWKS_REFRESH_INTERVAL", defaultJWKSRefreshInterval)
+	v.SetDefault("AUTH_ISSUER", "")
+	v.SetDefault("AUTH_AUDIENCE", "")
+	v.SetDefault("AUTH_LEEWAY", defaultLeeway)
+	v.SetDefault("AUTH_TENANT_CLAIM", "tenant_id")
+	v.SetDefault("AUTH_UNAUTHENTICATED_METHODS", defaultUnauthenticatedMethods)
+
+	config.LoadFromFile(v)
+
+	enabled := v.GetBool("AUTH_ENABLED")
+	jwks := v.GetString("AUTH_JWKS")
+	jwksRefreshInterval := v.GetDuration("AUTH_JWKS_REFRESH_INTERVAL")
+	issuer := v.GetString("AUTH_ISSUER")
+	audience := v.GetString("AUTH_AUDIENCE")
+	leeway := v.GetDuration("AUTH_LEEWAY")
+	tenantClaim := v.GetString("AUTH_TENANT_CLAIM")
+
+	unauthenticatedMethods := make([]string, 0)
+	for _, method := range strings.Split(v.GetString("AUTH_UNAUTHENTICATED_METHODS"), ",") {
+		if method = strings.TrimSpace(method); len(method) > 0 {
+			unauthenticatedMethods = append(unauthenticatedMethods, method)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"enabled":  enabled,
+		"jwks":     jwks,
+		"issuer":   issuer,
+		"audience": audience,
+	}).Debug("Auth Config Initialized")
+
+	return &Config{
+		Enabled:                enabled,
+		JWKS:                   jwks,
+		JWKSRefreshInterval:    jwksRefreshInterval,
+		Issuer:                 issuer,
+		Audience:               audience,
+		Leeway:                 leeway,
+		TenantClaim:            tenantClaim,
+		UnauthenticatedMethods: unauthenticatedMethods,
+	}
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Keys are loaded again at most this often when a token names an unknown key, so forged key ids can't hammer the source.
+	minJWKSRefreshInterval = 10 * time.Second
+	jwksFetchTimeout       = 10 * time.Second
+	maxJWKSSize            = 1 << 20
+)
+
+// keySet caches the public keys of a JSON Web Key Set, see RFC 7517.
+// Keys are loaded again once the refresh interval elapsed, or when a token is signed by an unknown key after a rotation.
+type keySet struct {
+	source          string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]interface{} // *rsa.PublicKey or *ecdsa.PublicKey, indexed by kid.
+	loadAttempt time.Time
+}
+
+func newKeySet(source string, refreshInterval time.Duration) *keySet {
+	return &keySet{
+		source:          source,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+// load replaces the cached keys, they are kept when the source can't be read.
+// The source is read without holding the lock, tokens are verified with the cached keys meanwhile.
+func (s *keySet) load(ctx context.Context) error {
+	s.mu.Lock()
+	s.loadAttempt = time.Now()
+	s.mu.Unlock()
+
+	raw, err := s.read(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	return nil
+}
+
+func (s *keySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(s.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint answered %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// key returns the key with the given id, a set holding a single key also verifies tokens without kid.
+func (s *keySet) key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	key, ok := s.lookup(kid)
+	sinceLoad := time.Since(s.loadAttempt)
+	refresh := sinceLoad > s.refreshInterval || (!ok && sinceLoad > minJWKSRefreshInterval)
+	if refresh {
+		// Claims the refresh, concurrent requests keep using the cached keys instead of reading the source too.
+		s.loadAttempt = time.Now()
+	}
+	s.mu.Unlock()
+
+	if refresh {
+		if err := s.load(ctx); err != nil {
+			logrus.WithError(err).WithField("jwks", s.source).Warn("Could not refresh JWKS, using the cached keys")
+		} else {
+			s.mu.Lock()
+			key, ok = s.lookup(kid)
+			s.mu.Unlock()
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (s *keySet) lookup(kid string) (interface{}, bool) {
+	if len(kid) == 0 && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the RSA and EC signing keys of the set, other keys are skipped.
+func parseJWKS(raw []byte) (map[string]interface{}, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logrus.WithError(err).WithField("kid", jwk.Kid).Warn("Skipping JWKS key")
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no usable signing key")
+	}
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point isn't on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
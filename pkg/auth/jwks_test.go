@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jwksServer serves a JWKS that can be changed, counting the requests it receives.
+type jwksServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	keys     []jsonWebKey
+	failing  bool
+	requests int
+}
+
+func newJWKSServer(t *testing.T, keys ...jsonWebKey) *jwksServer {
+	t.Helper()
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		if s.failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// serve replaces the keys of the set.
+func (s *jwksServer) serve(keys ...jsonWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func (s *jwksServer) fail(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func (s *jwksServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jsonWebKey {
+	return jsonWebKey{Kty: "RSA", Kid: kid, Use: "sig", N: encodeBigInt(key.N), E: encodeBigInt(big.NewInt(int64(key.E)))}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jsonWebKey {
+	return jsonWebKey{Kty: "EC", Kid: kid, Crv: "P-256", X: encodeBigInt(key.X), Y: encodeBigInt(key.Y)}
+}
+
+// loadedKeySet returns a keySet of the server, loaded as if it was a minute ago.
+func loadedKeySet(t *testing.T, server *jwksServer, refreshInterval time.Duration) *keySet {
+	t.Helper()
+	keys := newKeySet(server.URL, refreshInterval)
+	if err := keys.load(context.Background()); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	keys.loadAttempt = time.Now().Add(-time.Minute)
+	return keys
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	ecKey := newECKey(t)
+	offCurve := ecJWK("off", ecKey)
+	offCurve.Y = encodeBigInt(new(big.Int).Add(ecKey.Y, big.NewInt(1)))
+	encryption := rsaJWK("enc", rsaKey)
+	encryption.Use = "enc"
+
+	tests := []struct {
+		name     string
+		keys     []jsonWebKey
+		wantKids []string
+		wantErr  bool
+	}{
+		{name: "RSA and EC", keys: []jsonWebKey{rsaJWK("r", rsaKey), ecJWK("e", ecKey)}, wantKids: []string{"r", "e"}},
+		{name: "encryption key skipped", keys: []jsonWebKey{rsaJWK("r", rsaKey), encryption}, wantKids: []string{"r"}},
+		{name: "unsupported key type skipped", keys: []jsonWebKey{rsaJWK("r", rsaKey), {Kty: "oct", Kid: "hmac"}}, wantKids: []string{"r"}},
+		{name: "point off the curve skipped", keys: []jsonWebKey{rsaJWK("r", rsaKey), offCurve}, wantKids: []string{"r"}},
+		{name: "unsupported curve skipped", keys: []jsonWebKey{rsaJWK("r", rsaKey), {Kty: "EC", Kid: "k", Crv: "secp256k1"}}, wantKids: []string{"r"}},
+		{name: "no usable key", keys: []jsonWebKey{encryption}, wantErr: true},
+		{name: "empty", keys: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, _ := json.Marshal(map[string]interface{}{"keys": tt.keys})
+			keys, err := parseJWKS(raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJWKS() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(keys) != len(tt.wantKids) {
+				t.Errorf("parseJWKS() = %d keys, want %v", len(keys), tt.wantKids)
+			}
+			for _, kid := range tt.wantKids {
+				if _, ok := keys[kid]; !ok {
+					t.Errorf("parseJWKS() misses key %q", kid)
+				}
+			}
+		})
+	}
+
+	if _, err := parseJWKS([]byte("{")); err == nil {
+		t.Error("parseJWKS() of invalid JSON succeeded")
+	}
+}
+
+func TestKeySetLoad(t *testing.T) {
+	key := rsaJWK("a", newRSAKey(t))
+
+	server := newJWKSServer(t, key)
+	server.fail(true)
+	if err := newKeySet(server.URL, time.Hour).load(context.Background()); err == nil {
+		t.Error("load() from a failing endpoint succeeded")
+	}
+
+	raw, _ := json.Marshal(map[string]interface{}{"keys": []jsonWebKey{key}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	keys := newKeySet(path, time.Hour)
+	if err := keys.load(context.Background()); err != nil {
+		t.Fatalf("load() from a file: %v", err)
+	}
+	if _, err := keys.key(context.Background(), "a"); err != nil {
+		t.Errorf("key() of a file JWKS: %v", err)
+	}
+}
+
+func TestKeySetRefresh(t *testing.T) {
+	ctx := context.Background()
+	first := rsaJWK("first", newRSAKey(t))
+	second := ecJWK("second", newECKey(t))
+
+	t.Run("single key without kid", func(t *testing.T) {
+		keys := loadedKeySet(t, newJWKSServer(t, first), time.Hour)
+		if _, err := keys.key(ctx, ""); err != nil {
+			t.Errorf("key() without kid: %v", err)
+		}
+	})
+
+	t.Run("unknown key right after a load", func(t *testing.T) {
+		server := newJWKSServer(t, first)
+		keys := loadedKeySet(t, server, time.Hour)
+		keys.loadAttempt = time.Now()
+		server.serve(first, second)
+
+		if _, err := keys.key(ctx, "second"); err == nil {
+			t.Error("key() found a key without loading the set again")
+		}
+		if got := server.requestCount(); got != 1 {
+			t.Errorf("JWKS fetched %d times, want 1: unknown keys can't trigger loads that often", got)
+		}
+	})
+
+	t.Run("rotated key", func(t *testing.T) {
+		server := newJWKSServer(t, first)
+		keys := loadedKeySet(t, server, time.Hour)
+		server.serve(first, second)
+
+		if _, err := keys.key(ctx, "second"); err != nil {
+			t.Errorf("key() of a rotated key: %v", err)
+		}
+		if got := server.requestCount(); got != 2 {
+			t.Errorf("JWKS fetched %d times, want 2", got)
+		}
+	})
+
+	t.Run("refresh interval elapsed", func(t *testing.T) {
+		server := newJWKSServer(t, first)
+		keys := loadedKeySet(t, server, time.Second)
+		server.serve(second)
+
+		// The known key is dropped once the set is loaded again.
+		if _, err := keys.key(ctx, "first"); err == nil {
+			t.Error("key() still returns a key removed from the set")
+		}
+		if _, err := keys.key(ctx, "second"); err != nil {
+			t.Errorf("key() of the new key: %v", err)
+		}
+	})
+
+	t.Run("failing source keeps the cached keys", func(t *testing.T) {
+		server := newJWKSServer(t, first)
+		keys := loadedKeySet(t, server, time.Second)
+		server.fail(true)
+
+		if _, err := keys.key(ctx, "first"); err != nil {
+			t.Errorf("key() while the source fails: %v", err)
+		}
+		if got := server.requestCount(); got != 2 {
+			t.Errorf("JWKS fetched %d times, want 2", got)
+		}
+	})
+}
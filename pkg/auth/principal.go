@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Principal is the authenticated caller of a request, as described by its bearer token or API key.
+type Principal struct {
+	Subject    string
+	Issuer     string
+	Audience   []string
+	Scopes     []string               // From the space separated scope claim, the scp list, or the scopes of the API key.
+	ExpireTime time.Time              // Zero for API keys that never expire.
+	ApiKeyID   string                 // Set when authenticated with an API key instead of a token.
+	Tenant     string                 // From the tenant claim, or the tenant the API key was created in. Empty when none.
+	Claims     map[string]interface{} // All claims of the token, for the ones without a field. Empty for API keys.
+}
+
+// HasScope returns whether the token or API key was granted the given scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of the request, false when it wasn't authenticated.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,260 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/provider"
+	grpcProvider "learning/grpc-project-service/pkg/provider/grpc"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// Domain of the ErrorInfo details of the denials.
+const errorDomain = "platform.v1"
+
+// RoleResolver returns the membership roles a subject holds on a project, none when the project doesn't exist.
+type RoleResolver interface {
+	ProjectRoles(ctx context.Context, projectID string, subject string) ([]string, error)
+}
+
+// Authorizer enforces a Policy on the gRPC methods, after the callers were authenticated.
+type Authorizer struct {
+	provider.AbstractProvider
+
+	Config *Config
+	Roles  RoleResolver // Needed by policies with project_roles, must be set before the server runs.
+	policy *Policy
+}
+
+// New creates an Authorizer, its policy is loaded when it is initialized.
+func New(config *Config) *Authorizer {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Authorizer{Config: config}
+}
+
+func (a *Authorizer) Init() error {
+	if len(a.Config.Policy) == 0 {
+		logrus.Info("AUTHZ_POLICY not set, every authenticated request is authorized")
+		return nil
+	}
+
+	policy, err := LoadPolicy(a.Config.Policy)
+	if err != nil {
+		return err
+	}
+	a.policy = policy
+	return nil
+}
+
+// CustomOpts returns the interceptors of the Authorizer, to be given to the grpc Server.
+func (a *Authorizer) CustomOpts() grpcProvider.CustomOpts {
+	return grpcProvider.CustomOpts{
+		UnaryInterceptor:  []grpc.UnaryServerInterceptor{a.unaryServerInterceptor},
+		StreamInterceptor: []grpc.StreamServerInterceptor{a.streamServerInterceptor},
+	}
+}
+
+func (a *Authorizer) unaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *Authorizer) streamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if a.policy != nil && a.policy.needsRequest(info.FullMethod) && !info.IsClientStream {
+		// The projects are only known once the request was received.
+		return handler(srv, &authorizedStream{ServerStream: stream, authorizer: a, method: info.FullMethod})
+	}
+	if err := a.authorize(stream.Context(), info.FullMethod, nil); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// authorizedStream authorizes a server streaming call with its request, before the handler can use it.
+type authorizedStream struct {
+	grpc.ServerStream
+	authorizer *Authorizer
+	method     string
+	authorized bool
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.authorized {
+		if err := s.authorizer.authorize(s.Context(), s.method, m); err != nil {
+			return err
+		}
+		s.authorized = true
+	}
+	return nil
+}
+
+// authorize returns a PermissionDenied error unless the policy allows the caller to call the method with the request.
+func (a *Authorizer) authorize(ctx context.Context, method string, req interface{}) error {
+	if a.policy == nil {
+		return nil
+	}
+
+	principal, _ := auth.FromContext(ctx)
+	projectIDs := requestProjects(req)
+	tenantWide := a.isTenantWide(method)
+	for _, rule := range a.policy.Rules {
+		if !rule.matchesMethod(method) {
+			continue
+		}
+		var ok bool
+		var err error
+		if tenantWide && rule.Effect == EffectAllow {
+			ok = principal != nil && containsAny(TokenRoles(principal, a.Config.RolesClaim), rule.Roles)
+		} else {
+			ok, err = a.matchesCaller(ctx, rule, principal, projectIDs)
+		}
+		if err != nil {
+			return err
+		}
+		if ok {
+			if rule.Effect == EffectAllow && hasAnyScope(principal, rule.Scopes) {
+				return nil
+			}
+			return permissionDenied(method, projectIDs)
+		}
+	}
+
+	if a.policy.Default == EffectAllow && !tenantWide {
+		return nil
+	}
+	return permissionDenied(method, projectIDs)
+}
+
+// isTenantWide returns whether the method matches one of Config.TenantWideMethods.
+func (a *Authorizer) isTenantWide(method string) bool {
+	return util.MatchAnyMethod(a.Config.TenantWideMethods, method)
+}
+
+func (a *Authorizer) matchesCaller(ctx context.Context, rule *Rule, principal *auth.Principal, projectIDs []string) (bool, error) {
+	if !rule.Authenticated && len(rule.Roles) == 0 && len(rule.ProjectRoles) == 0 {
+		return true, nil
+	}
+	if principal == nil {
+		return false, nil
+	}
+	if rule.Authenticated || containsAny(TokenRoles(principal, a.Config.RolesClaim), rule.Roles) {
+		return true, nil
+	}
+	if len(rule.ProjectRoles) == 0 || len(projectIDs) == 0 || a.Roles == nil {
+		return false, nil
+	}
+
+	for _, projectID := range projectIDs {
+		roles, err := a.Roles.ProjectRoles(ctx, projectID, principal.Subject)
+		if err != nil {
+			return false, err
+		}
+		if !containsAny(roles, rule.ProjectRoles) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// requestProjects returns the ids of the projects targeted by a request, none when it isn't about existing projects.
+func requestProjects(req interface{}) []string {
+	switch r := req.(type) {
+	case interface{ GetProjectId() string }:
+		return []string{r.GetProjectId()}
+	case interface{ GetProjectIds() []string }:
+		return r.GetProjectIds()
+	default:
+		return nil
+	}
+}
+
+// hasAnyScope returns whether the caller was granted one of the scopes, always true when there are none.
+func hasAnyScope(principal *auth.Principal, scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	if principal == nil {
+		return false
+	}
+	for _, scope := range scopes {
+		if principal.HasScope(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenRoles returns the roles of the caller, held by the given claim of its token. API keys have none.
+func TokenRoles(principal *auth.Principal, claim string) []string {
+	switch roles := principal.Claims[claim].(type) {
+	case string:
+		return strings.Fields(roles)
+	case []interface{}:
+		result := make([]string, 0, len(roles))
+		for _, role := range roles {
+			if role, ok := role.(string); ok {
+				result = append(result, role)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Permission returns the name of the permission needed to call a method, eg: platform.v1.ProjectAPI.GetProject.
+func Permission(method string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", ".")
+}
+
+// permissionDenied returns a PermissionDenied error with a google.rpc.ErrorInfo detail naming the missing permission.
+func permissionDenied(method string, projectIDs []string) error {
+	permission := Permission(method)
+	metadata := map[string]string{"permission": permission}
+	message := fmt.Sprintf("permission %s denied", permission)
+	if len(projectIDs) > 0 {
+		resources := make([]string, 0, len(projectIDs))
+		for _, id := range projectIDs {
+			resources = append(resources, "projects/"+id)
+		}
+		metadata["resource"] = strings.Join(resources, ",")
+		message += " on " + metadata["resource"]
+	}
+
+	st := status.New(codes.PermissionDenied, message)
+	ds, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   "IAM_PERMISSION_DENIED",
+		Domain:   errorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
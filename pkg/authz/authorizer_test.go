@@ -0,0 +1,195 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+	"learning/grpc-project-service/pkg/auth"
+)
+
+const testPolicy = `
+default: deny
+rules:
+  - methods: ["/grpc.health.v1.Health/"]
+    effect: allow
+  - methods: ["/platform.v1.ProjectAPI/DeleteProject"]
+    effect: deny
+    roles: [suspended]
+  - methods: ["/platform.v1.ProjectAPI/", "/platform.v1.WebhookAPI/"]
+    effect: allow
+    roles: [admin]
+  - methods: ["/platform.v1.ProjectAPI/CreateProject", "/platform.v1.ProjectAPI/ListProjects"]
+    effect: allow
+    authenticated: true
+  - methods: ["/platform.v1.ProjectAPI/GetProject", "/platform.v1.ProjectAPI/BatchGetProjects"]
+    effect: allow
+    project_roles: [VIEWER, EDITOR, OWNER]
+    scopes: [projects.read]
+  - methods: ["/platform.v1.ProjectAPI/DeleteProject"]
+    effect: allow
+    project_roles: [OWNER]
+  - methods: ["/platform.v1.WebhookAPI/"]
+    effect: allow
+`
+
+// projectRoles resolves the roles of subjects on projects from a map indexed by project then subject.
+type projectRoles map[string]map[string][]string
+
+func (r projectRoles) ProjectRoles(_ context.Context, projectID string, subject string) ([]string, error) {
+	return r[projectID][subject], nil
+}
+
+func newTestAuthorizer(t *testing.T, policy string) *Authorizer {
+	t.Helper()
+	a := New(&Config{
+		Policy:            writePolicy(t, policy),
+		RolesClaim:        "roles",
+		TenantWideMethods: []string{"/platform.v1.ProjectAPI/ListProjects", "/platform.v1.WebhookAPI/"},
+	})
+	a.Roles = projectRoles{
+		"p1": {"viewer": {"VIEWER"}, "owner": {"OWNER"}},
+		"p2": {"viewer": {"VIEWER"}},
+	}
+	if err := a.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	return a
+}
+
+// caller returns the context of a request of subject, with the given token roles and scopes.
+func caller(subject string, roles []interface{}, scopes ...string) context.Context {
+	return auth.NewContext(context.Background(), &auth.Principal{
+		Subject: subject,
+		Scopes:  scopes,
+		Claims:  map[string]interface{}{"roles": roles},
+	})
+}
+
+func TestAuthorize(t *testing.T) {
+	a := newTestAuthorizer(t, testPolicy)
+	anonymous := context.Background()
+	admin := caller("admin", []interface{}{"admin"})
+	suspendedAdmin := caller("admin", []interface{}{"admin", "suspended"})
+	user := caller("user", nil)
+	viewer := caller("viewer", nil, "projects.read")
+	owner := caller("owner", nil, "projects.read")
+
+	get := func(id string) *pb.GetProjectRequest { return &pb.GetProjectRequest{ProjectId: id} }
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		req    interface{}
+		want   bool
+	}{
+		{name: "anyone on health", ctx: anonymous, method: "/grpc.health.v1.Health/Check", want: true},
+		{name: "default deny", ctx: admin, method: "/platform.v1.MemberAPI/AddMember", want: false},
+		{name: "token role", ctx: admin, method: "/platform.v1.ProjectAPI/UpdateProject", req: &pb.UpdateProjectRequest{ProjectId: "p1"}, want: true},
+		{name: "deny rule first", ctx: suspendedAdmin, method: "/platform.v1.ProjectAPI/DeleteProject", req: &pb.DeleteProjectRequest{ProjectId: "p1"}, want: false},
+		{name: "authenticated", ctx: user, method: "/platform.v1.ProjectAPI/CreateProject", want: true},
+		{name: "anonymous on authenticated", ctx: anonymous, method: "/platform.v1.ProjectAPI/CreateProject", want: false},
+		{name: "project role", ctx: viewer, method: "/platform.v1.ProjectAPI/GetProject", req: get("p1"), want: true},
+		{name: "project role without scope", ctx: caller("viewer", nil), method: "/platform.v1.ProjectAPI/GetProject", req: get("p1"), want: false},
+		{name: "project role with other scopes", ctx: caller("viewer", nil, "projects.write"), method: "/platform.v1.ProjectAPI/GetProject", req: get("p1"), want: false},
+		{name: "not a member", ctx: caller("user", nil, "projects.read"), method: "/platform.v1.ProjectAPI/GetProject", req: get("p1"), want: false},
+		{name: "unknown project", ctx: viewer, method: "/platform.v1.ProjectAPI/GetProject", req: get("p9"), want: false},
+		{name: "anonymous on project role", ctx: anonymous, method: "/platform.v1.ProjectAPI/GetProject", req: get("p1"), want: false},
+		{name: "role on every project", ctx: viewer, method: "/platform.v1.ProjectAPI/BatchGetProjects", req: &pb.BatchGetProjectsRequest{ProjectIds: []string{"p1", "p2"}}, want: true},
+		{name: "role on some projects", ctx: owner, method: "/platform.v1.ProjectAPI/BatchGetProjects", req: &pb.BatchGetProjectsRequest{ProjectIds: []string{"p1", "p2"}}, want: false},
+		{name: "other project role", ctx: viewer, method: "/platform.v1.ProjectAPI/DeleteProject", req: &pb.DeleteProjectRequest{ProjectId: "p1"}, want: false},
+		{name: "owner role", ctx: owner, method: "/platform.v1.ProjectAPI/DeleteProject", req: &pb.DeleteProjectRequest{ProjectId: "p1"}, want: true},
+		// Tenant wide methods are only allowed by token roles.
+		{name: "tenant wide with token role", ctx: admin, method: "/platform.v1.ProjectAPI/ListProjects", req: &pb.ListProjectsRequest{}, want: true},
+		{name: "tenant wide authenticated", ctx: user, method: "/platform.v1.ProjectAPI/ListProjects", req: &pb.ListProjectsRequest{}, want: false},
+		{name: "tenant wide service with token role", ctx: admin, method: "/platform.v1.WebhookAPI/ListWebhooks", want: true},
+		{name: "tenant wide service for anyone", ctx: user, method: "/platform.v1.WebhookAPI/ListWebhooks", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.authorize(tt.ctx, tt.method, tt.req)
+			if tt.want {
+				if err != nil {
+					t.Errorf("authorize() error = %v, want allowed", err)
+				}
+				return
+			}
+			if status.Code(err) != codes.PermissionDenied {
+				t.Errorf("authorize() error = %v, want PermissionDenied", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeDefaultAllow(t *testing.T) {
+	a := newTestAuthorizer(t, `
+default: allow
+rules:
+  - methods: ["/platform.v1.ProjectAPI/DeleteProject"]
+    effect: deny
+`)
+
+	if err := a.authorize(context.Background(), "/platform.v1.ProjectAPI/GetProject", nil); err != nil {
+		t.Errorf("authorize() without matching rule = %v, want allowed", err)
+	}
+	if err := a.authorize(context.Background(), "/platform.v1.ProjectAPI/DeleteProject", nil); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("authorize() of a denied method = %v, want PermissionDenied", err)
+	}
+	// The default doesn't reveal the projects of the tenant.
+	if err := a.authorize(caller("user", nil), "/platform.v1.ProjectAPI/ListProjects", nil); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("authorize() of a tenant wide method = %v, want PermissionDenied", err)
+	}
+}
+
+func TestPermissionDenied(t *testing.T) {
+	a := newTestAuthorizer(t, testPolicy)
+
+	err := a.authorize(caller("user", nil), "/platform.v1.ProjectAPI/BatchGetProjects", &pb.BatchGetProjectsRequest{ProjectIds: []string{"p1", "p2"}})
+	st := status.Convert(err)
+	if st.Code() != codes.PermissionDenied || st.Message() != "permission platform.v1.ProjectAPI.BatchGetProjects denied on projects/p1,projects/p2" {
+		t.Fatalf("authorize() error = %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.ErrorInfo); ok {
+			info = d
+		}
+	}
+	if info == nil || info.Reason != "IAM_PERMISSION_DENIED" || info.Domain != errorDomain ||
+		info.Metadata["permission"] != "platform.v1.ProjectAPI.BatchGetProjects" || info.Metadata["resource"] != "projects/p1,projects/p2" {
+		t.Errorf("ErrorInfo = %v", info)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a := newTestAuthorizer(t, testPolicy)
+	info := &grpc.UnaryServerInfo{FullMethod: "/platform.v1.ProjectAPI/GetProject"}
+
+	called := false
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+	if _, err := a.unaryServerInterceptor(caller("user", nil, "projects.read"), &pb.GetProjectRequest{ProjectId: "p1"}, info, handler); status.Code(err) != codes.PermissionDenied || called {
+		t.Errorf("interceptor error = %v, handler called %v, want PermissionDenied without the handler", err, called)
+	}
+	if _, err := a.unaryServerInterceptor(caller("viewer", nil, "projects.read"), &pb.GetProjectRequest{ProjectId: "p1"}, info, handler); err != nil || !called {
+		t.Errorf("interceptor error = %v, handler called %v, want the handler", err, called)
+	}
+}
+
+func TestAuthorizeWithoutPolicy(t *testing.T) {
+	a := New(&Config{})
+	if err := a.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if err := a.authorize(context.Background(), "/platform.v1.ProjectAPI/DeleteProject", nil); err != nil {
+		t.Errorf("authorize() without policy = %v, want allowed", err)
+	}
+}
@@ -0,0 +1,45 @@
+package authz
+
+import (
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const defaultRolesClaim = "roles"
+
+// Config configuration of the Authorizer.
+type Config struct {
+	Policy     string // Path of the YAML policy, authorization is disabled when empty.
+	RolesClaim string // Token claim holding the roles of the caller, either a list or a space separated string.
+	// Full names of the methods returning the data of every project of the tenant, whatever the memberships of the caller,
+	// /package.Service/ matches all methods of the service, see util.MatchMethod.
+	// Only allowed by rules with token roles: project_roles can't tell which projects they reveal,
+	// authenticated rules, rules matching everyone and a default allow would reveal them to any caller.
+	TenantWideMethods []string
+}
+
+// NewConfigFromEnv initializes the configuration from environment variables or config file.
+func NewConfigFromEnv() *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	v.SetDefault("AUTHZ_POLICY", "")
+	v.SetDefault("AUTHZ_ROLES_CLAIM", defaultRolesClaim)
+
+	config.LoadFromFile(v)
+
+	policy := v.GetString("AUTHZ_POLICY")
+	rolesClaim := v.GetString("AUTHZ_ROLES_CLAIM")
+
+	logrus.WithFields(logrus.Fields{
+		"policy":     policy,
+		"rolesClaim": rolesClaim,
+	}).Debug("Authz Config Initialized")
+
+	return &Config{
+		Policy:     policy,
+		RolesClaim: rolesClaim,
+	}
+}
@@ -0,0 +1,106 @@
+package authz
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"learning/grpc-project-service/pkg/util"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Policy decides which callers may call which gRPC methods, eg:
+//
+//	default: deny
+//	rules:
+//	  - methods: ["/grpc.health.v1.Health/"]
+//	    effect: allow
+//	  - methods: ["/platform.v1.ProjectAPI/"]
+//	    effect: allow
+//	    roles: [admin]
+//	  - methods: ["/platform.v1.ProjectAPI/CreateProject"]
+//	    effect: allow
+//	    authenticated: true
+//	  - methods: ["/platform.v1.ProjectAPI/GetProject"]
+//	    effect: allow
+//	    project_roles: [VIEWER, EDITOR, OWNER]
+//	    scopes: [projects.read]
+//
+// Rules are evaluated in order, the first one matching both the method and the caller decides.
+// An allow rule with scopes denies the callers granted none of them, eg: an API key created with other scopes.
+// Methods of Config.TenantWideMethods, eg: ListProjects and WatchProjects, are only allowed by the roles of a rule,
+// only admins can call them with the policy above.
+type Policy struct {
+	Default string  `yaml:"default"` // Effect when no rule matches, deny when empty.
+	Rules   []*Rule `yaml:"rules"`
+}
+
+// Rule matches a caller holding any of its roles, project roles, or any authenticated caller when Authenticated is set.
+// A rule without any of them matches every caller, anonymous ones included.
+type Rule struct {
+	// Full method names, /package.Service/ matches all methods of the service and / all methods, see util.MatchMethod.
+	Methods       []string `yaml:"methods"`
+	Effect        string   `yaml:"effect"`
+	Authenticated bool     `yaml:"authenticated"`
+	Roles         []string `yaml:"roles"` // Roles of the token, see Config.RolesClaim.
+	// Membership roles, held on every project targeted by the request. Never matches requests without a project.
+	ProjectRoles []string `yaml:"project_roles"`
+	// Scopes of the token or API key, the caller must hold one of them to be allowed. Not used to match the caller:
+	// once the rule matches, callers without any of them are denied, callers without scopes included.
+	Scopes []string `yaml:"scopes"`
+}
+
+// LoadPolicy reads and validates a YAML policy, unknown fields are rejected so a typo can't silently widen access.
+func LoadPolicy(path string) (*Policy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := new(Policy)
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+
+	if len(policy.Default) == 0 {
+		policy.Default = EffectDeny
+	}
+	if policy.Default != EffectAllow && policy.Default != EffectDeny {
+		return nil, fmt.Errorf("invalid policy %s: default must be %s or %s", path, EffectAllow, EffectDeny)
+	}
+	for i, rule := range policy.Rules {
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return nil, fmt.Errorf("invalid policy %s: rules[%d].effect must be %s or %s", path, i, EffectAllow, EffectDeny)
+		}
+		if len(rule.Methods) == 0 {
+			return nil, fmt.Errorf("invalid policy %s: rules[%d].methods can't be empty", path, i)
+		}
+		for j, method := range rule.Methods {
+			if err := util.ValidateMethodPattern(method); err != nil {
+				return nil, fmt.Errorf("invalid policy %s: rules[%d].methods[%d]: %w", path, i, j, err)
+			}
+		}
+	}
+	return policy, nil
+}
+
+func (r *Rule) matchesMethod(method string) bool {
+	return util.MatchAnyMethod(r.Methods, method)
+}
+
+// needsRequest returns whether a rule of the method depends on the projects targeted by the request.
+func (p *Policy) needsRequest(method string) bool {
+	for _, rule := range p.Rules {
+		if len(rule.ProjectRoles) > 0 && rule.matchesMethod(method) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package authz
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePolicy writes a YAML policy to a temporary file and returns its path.
+func writePolicy(t *testing.T, policy string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestLoadPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		wantErr     string // Part of the error, empty when the policy is valid.
+		wantDefault string
+		wantRules   int
+	}{
+		{
+			name: "valid",
+			policy: `
+default: allow
+rules:
+  - methods: ["/grpc.health.v1.Health/", "/"]
+    effect: allow
+  - methods: ["/platform.v1.ProjectAPI/GetProject"]
+    effect: deny
+    authenticated: true
+    roles: [admin]
+    project_roles: [VIEWER]
+    scopes: [projects.read]
+`,
+			wantDefault: EffectAllow,
+			wantRules:   2,
+		},
+		{name: "deny by default", policy: "rules: []", wantDefault: EffectDeny},
+		{name: "invalid default", policy: "default: maybe", wantErr: "default must be"},
+		{name: "invalid effect", policy: `rules: [{methods: ["/"], effect: permit}]`, wantErr: "rules[0].effect"},
+		{name: "no methods", policy: `rules: [{effect: allow}]`, wantErr: "rules[0].methods can't be empty"},
+		{name: "unknown field", policy: `rules: [{methods: ["/"], effect: allow, role: [admin]}]`, wantErr: "field role not found"},
+		{name: "wildcard service", policy: `rules: [{methods: ["/platform.v1.ProjectAPI/*"], effect: allow}]`, wantErr: "rules[0].methods[0]"},
+		{name: "wildcard", policy: `rules: [{methods: ["*"], effect: allow}]`, wantErr: "rules[0].methods[0]"},
+		{name: "not a full method name", policy: `rules: [{methods: ["/", "platform.v1.ProjectAPI/GetProject"], effect: allow}]`, wantErr: "rules[0].methods[1]"},
+		{name: "not YAML", policy: "rules: [", wantErr: "invalid policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := LoadPolicy(writePolicy(t, tt.policy))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadPolicy() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadPolicy() error = %v", err)
+			}
+			if policy.Default != tt.wantDefault || len(policy.Rules) != tt.wantRules {
+				t.Errorf("LoadPolicy() = default %s with %d rules, want %s with %d", policy.Default, len(policy.Rules), tt.wantDefault, tt.wantRules)
+			}
+		})
+	}
+
+	if _, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadPolicy() of a missing file succeeded")
+	}
+}
+
+func TestRuleMatchesMethod(t *testing.T) {
+	tests := []struct {
+		methods []string
+		method  string
+		want    bool
+	}{
+		{methods: []string{"/platform.v1.ProjectAPI/GetProject"}, method: "/platform.v1.ProjectAPI/GetProject", want: true},
+		{methods: []string{"/platform.v1.ProjectAPI/GetProject"}, method: "/platform.v1.ProjectAPI/GetProjects", want: false},
+		{methods: []string{"/platform.v1.ProjectAPI/"}, method: "/platform.v1.ProjectAPI/GetProject", want: true},
+		{methods: []string{"/platform.v1.ProjectAPI/"}, method: "/platform.v1.ProjectAPIv2/GetProject", want: false},
+		{methods: []string{"/platform.v1.ProjectAPI"}, method: "/platform.v1.ProjectAPI/GetProject", want: false},
+		{methods: []string{"/platform.v1.MemberAPI/", "/platform.v1.ProjectAPI/"}, method: "/platform.v1.ProjectAPI/GetProject", want: true},
+		{methods: []string{"/"}, method: "/grpc.health.v1.Health/Check", want: true},
+	}
+
+	for _, tt := range tests {
+		rule := &Rule{Methods: tt.methods}
+		if got := rule.matchesMethod(tt.method); got != tt.want {
+			t.Errorf("%v matches %s = %v, want %v", tt.methods, tt.method, got, tt.want)
+		}
+	}
+}
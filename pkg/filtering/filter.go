@@ -0,0 +1,346 @@
+package filtering
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldType type of a filterable field, it determines the accepted comparators and values.
+type FieldType int
+
+const (
+	TypeString    FieldType = iota // Supports all comparators, "*" acts as a wildcard for = != and :
+	TypeUUID                       // Stored as a UUID, supports = and !=.
+	TypeTimestamp                  // RFC 3339 values, supports = != < <= > >=.
+	TypeMap                        // map<string,string>, `labels.env = "prod"` compares a value and `labels:env` checks a key.
+)
+
+// defaultMapKeyPattern keys accepted by TypeMap fields without a KeyPattern.
+var defaultMapKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Field describes how a filterable API field is stored.
+type Field struct {
+	Key  string // Document key, eg: "createTime".
+	Type FieldType
+	// Keys accepted by a TypeMap field, defaultMapKeyPattern when nil.
+	// Keys become part of the document key, a pattern must never accept "." or "$".
+	KeyPattern *regexp.Regexp
+}
+
+// Schema filterable fields, indexed by API field name.
+type Schema map[string]Field
+
+// Getter returns the value of a field of the element being evaluated in memory.
+// key is only set for TypeMap fields. Values must be a string, or a time.Time for TypeTimestamp fields.
+type Getter func(field, key string) (value interface{}, ok bool)
+
+// Filter a parsed and type checked filter.
+type Filter struct {
+	root node
+}
+
+// Parse parses an AIP-160 filter and checks it against the schema.
+// An empty filter returns a Filter matching everything. Errors are of type *Error.
+func Parse(filter string, schema Schema) (*Filter, error) {
+	expr, err := ParseExpr(filter)
+	if err != nil {
+		return nil, err
+	}
+	if expr == nil {
+		return &Filter{}, nil
+	}
+
+	root, err := compile(expr, schema)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{root: root}, nil
+}
+
+// BSON translates the filter into a MongoDB query document.
+func (f *Filter) BSON() bson.M {
+	if f == nil || f.root == nil {
+		return bson.M{}
+	}
+	return f.root.bson()
+}
+
+// Match evaluates the filter in memory.
+func (f *Filter) Match(get Getter) bool {
+	if f == nil || f.root == nil {
+		return true
+	}
+	return f.root.match(get)
+}
+
+type node interface {
+	bson() bson.M
+	match(Getter) bool
+}
+
+type (
+	andNode     []node
+	orNode      []node
+	notNode     struct{ arg node }
+	restriction struct {
+		field      string
+		key        string // Map key, only for TypeMap fields.
+		docKey     string
+		typ        FieldType
+		comparator string
+		value      interface{}    // string or time.Time, used in memory.
+		bsonValue  interface{}    // Value as stored in MongoDB.
+		pattern    *regexp.Regexp // Set when a string value contains wildcards.
+		exists     bool           // Presence check, eg: `labels:env`.
+	}
+)
+
+func compile(expr Expr, schema Schema) (node, error) {
+	switch e := expr.(type) {
+	case *AndExpr:
+		args, err := compileAll(e.Args, schema)
+		return andNode(args), err
+	case *OrExpr:
+		args, err := compileAll(e.Args, schema)
+		return orNode(args), err
+	case *NotExpr:
+		arg, err := compile(e.Arg, schema)
+		return &notNode{arg: arg}, err
+	case *Restriction:
+		return compileRestriction(e, schema)
+	}
+	return nil, newError(expr.Pos(), "unsupported expression")
+}
+
+func compileAll(exprs []Expr, schema Schema) ([]node, error) {
+	nodes := make([]node, 0, len(exprs))
+	for _, expr := range exprs {
+		n, err := compile(expr, schema)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+func compileRestriction(e *Restriction, schema Schema) (node, error) {
+	name := e.Path[0]
+	field, ok := schema[name]
+	if !ok {
+		return nil, newError(e.pos, "unknown field %q", name)
+	}
+
+	r := &restriction{field: name, docKey: field.Key, typ: field.Type, comparator: e.Comparator}
+
+	if field.Type == TypeMap {
+		keyPattern := field.KeyPattern
+		if keyPattern == nil {
+			keyPattern = defaultMapKeyPattern
+		}
+		switch {
+		case len(e.Path) == 1 && e.Comparator == ":":
+			// `labels:env` checks the presence of a key.
+			if !keyPattern.MatchString(e.Value) {
+				return nil, newError(e.ValuePos, "invalid key %q for field %q", e.Value, name)
+			}
+			r.key, r.exists = e.Value, true
+			r.docKey = field.Key + "." + e.Value
+			return r, nil
+		case len(e.Path) == 2:
+			if !keyPattern.MatchString(e.Path[1]) {
+				return nil, newError(e.pos, "invalid key %q for field %q", e.Path[1], name)
+			}
+			r.key = e.Path[1]
+			r.docKey = field.Key + "." + r.key
+		default:
+			return nil, newError(e.pos, "field %q must be used as %s.<key> or %s:<key>", name, name, name)
+		}
+	} else if len(e.Path) > 1 {
+		return nil, newError(e.pos, "field %q has no sub fields", name)
+	}
+
+	switch field.Type {
+	case TypeString, TypeMap:
+		if e.Comparator == ":" && e.Value == "*" {
+			r.exists = true
+			return r, nil
+		}
+		r.value, r.bsonValue = e.Value, e.Value
+		if e.Comparator == "=" || e.Comparator == "!=" || e.Comparator == ":" {
+			r.pattern = wildcardPattern(e.Value)
+		}
+		if e.Comparator == ":" {
+			r.comparator = "="
+		}
+	case TypeUUID:
+		if e.Comparator != "=" && e.Comparator != "!=" {
+			return nil, newError(e.pos, "comparator %q is not supported for field %q", e.Comparator, name)
+		}
+		id, err := uuid.FromString(e.Value)
+		if err != nil {
+			return nil, newError(e.ValuePos, "invalid UUID %q for field %q", e.Value, name)
+		}
+		r.value, r.bsonValue = id.String(), id
+	case TypeTimestamp:
+		if e.Comparator == ":" {
+			return nil, newError(e.pos, "comparator %q is not supported for field %q", e.Comparator, name)
+		}
+		t, err := time.Parse(time.RFC3339Nano, e.Value)
+		if err != nil {
+			return nil, newError(e.ValuePos, "invalid timestamp %q for field %q, expected RFC 3339", e.Value, name)
+		}
+		r.value, r.bsonValue = t.UTC(), t.UTC()
+	}
+	return r, nil
+}
+
+// wildcardPattern returns an anchored regular expression when the value contains "*" wildcards.
+func wildcardPattern(value string) *regexp.Regexp {
+	if !strings.Contains(value, "*") {
+		return nil
+	}
+	parts := strings.Split(value, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+func (n andNode) bson() bson.M {
+	args := bson.A{}
+	for _, arg := range n {
+		args = append(args, arg.bson())
+	}
+	return bson.M{"$and": args}
+}
+
+func (n andNode) match(get Getter) bool {
+	for _, arg := range n {
+		if !arg.match(get) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n orNode) bson() bson.M {
+	args := bson.A{}
+	for _, arg := range n {
+		args = append(args, arg.bson())
+	}
+	return bson.M{"$or": args}
+}
+
+func (n orNode) match(get Getter) bool {
+	for _, arg := range n {
+		if arg.match(get) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *notNode) bson() bson.M {
+	return bson.M{"$nor": bson.A{n.arg.bson()}}
+}
+
+func (n *notNode) match(get Getter) bool {
+	return !n.arg.match(get)
+}
+
+func (r *restriction) bson() bson.M {
+	if r.exists {
+		if r.typ == TypeString {
+			return bson.M{r.docKey: bson.M{"$exists": true, "$ne": ""}}
+		}
+		return bson.M{r.docKey: bson.M{"$exists": true}}
+	}
+
+	if r.pattern != nil {
+		regex := primitive.Regex{Pattern: r.pattern.String()}
+		if r.comparator == "!=" {
+			return bson.M{r.docKey: bson.M{"$not": regex}}
+		}
+		return bson.M{r.docKey: regex}
+	}
+
+	switch r.comparator {
+	case "=":
+		return bson.M{r.docKey: r.bsonValue}
+	case "!=":
+		return bson.M{r.docKey: bson.M{"$ne": r.bsonValue}}
+	case "<":
+		return bson.M{r.docKey: bson.M{"$lt": r.bsonValue}}
+	case "<=":
+		return bson.M{r.docKey: bson.M{"$lte": r.bsonValue}}
+	case ">":
+		return bson.M{r.docKey: bson.M{"$gt": r.bsonValue}}
+	case ">=":
+		return bson.M{r.docKey: bson.M{"$gte": r.bsonValue}}
+	}
+	return bson.M{}
+}
+
+func (r *restriction) match(get Getter) bool {
+	value, ok := get(r.field, r.key)
+	if r.exists {
+		s, isString := value.(string)
+		return ok && (!isString || r.typ != TypeString || s != "")
+	}
+	if !ok {
+		// Like MongoDB, only a negative comparison matches a missing field.
+		return r.comparator == "!="
+	}
+
+	if r.pattern != nil {
+		s, _ := value.(string)
+		return r.pattern.MatchString(s) == (r.comparator == "=")
+	}
+
+	c, comparable := compareValues(value, r.value)
+	if !comparable {
+		return r.comparator == "!="
+	}
+	switch r.comparator {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func compareValues(a, b interface{}) (int, bool) {
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return strings.Compare(av, bv), ok
+	case time.Time:
+		bv, ok := b.(time.Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av.Before(bv):
+			return -1, true
+		case av.After(bv):
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
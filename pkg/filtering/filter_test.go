@@ -0,0 +1,188 @@
+package filtering
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testSchema = Schema{
+	"id":          {Key: "_id", Type: TypeUUID},
+	"name":        {Key: "name", Type: TypeString},
+	"labels":      {Key: "labels", Type: TypeMap, KeyPattern: regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)},
+	"create_time": {Key: "createTime", Type: TypeTimestamp},
+}
+
+// testElement is an element evaluated in memory by the tests.
+type testElement struct {
+	id         string
+	name       string
+	labels     map[string]string
+	createTime time.Time
+}
+
+func (e *testElement) get(field, key string) (interface{}, bool) {
+	switch field {
+	case "id":
+		return e.id, true
+	case "name":
+		return e.name, true
+	case "labels":
+		value, ok := e.labels[key]
+		return value, ok
+	case "create_time":
+		return e.createTime, true
+	}
+	return nil, false
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		pos    int
+	}{
+		{filter: `name`, pos: 5},
+		{filter: `name =`, pos: 7},
+		{filter: `"infra"`, pos: 1},
+		{filter: `name = "infra`, pos: 8},
+		{filter: `(name = "a"`, pos: 12},
+		{filter: `name = "a" AND`, pos: 15},
+		{filter: `name ! "a"`, pos: 6},
+		{filter: `name = "a")`, pos: 11},
+		{filter: `unknown = "a"`, pos: 1},
+		{filter: `name.first = "a"`, pos: 1},
+		{filter: `id = "not-a-uuid"`, pos: 6},
+		{filter: `id > "5f0d8a5e-6c1e-4f6e-9a39-3f6b0e9b9f0a"`, pos: 1},
+		{filter: `create_time > "yesterday"`, pos: 15},
+		{filter: `create_time:"2026-01-01T00:00:00Z"`, pos: 1},
+		{filter: `labels = "prod"`, pos: 1},
+		{filter: `labels.env.x = "prod"`, pos: 1},
+		{filter: `labels:"a.b"`, pos: 8},
+		{filter: `labels:"$where"`, pos: 8},
+		{filter: `labels:Env`, pos: 8},
+		{filter: `labels.Env = "prod"`, pos: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := Parse(tt.filter, testSchema)
+			var filterErr *Error
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("Parse() error = %v, want an *Error", err)
+			}
+			if filterErr.Pos != tt.pos {
+				t.Errorf("Parse() error %q at position %d, want %d", filterErr.Message, filterErr.Pos, tt.pos)
+			}
+		})
+	}
+}
+
+func TestFilterBSON(t *testing.T) {
+	id := uuid.Must(uuid.FromString("5f0d8a5e-6c1e-4f6e-9a39-3f6b0e9b9f0a"))
+	createTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		filter string
+		want   bson.M
+	}{
+		{filter: ``, want: bson.M{}},
+		{filter: `name = "infra"`, want: bson.M{"name": "infra"}},
+		{filter: `name != "infra"`, want: bson.M{"name": bson.M{"$ne": "infra"}}},
+		{filter: `name:"infra*"`, want: bson.M{"name": primitive.Regex{Pattern: `^infra.*$`}}},
+		{filter: `name != "a.b*"`, want: bson.M{"name": bson.M{"$not": primitive.Regex{Pattern: `^a\.b.*$`}}}},
+		{filter: `name:*`, want: bson.M{"name": bson.M{"$exists": true, "$ne": ""}}},
+		{filter: `id = "5f0d8a5e-6c1e-4f6e-9a39-3f6b0e9b9f0a"`, want: bson.M{"_id": id}},
+		{filter: `create_time >= "2026-01-01T01:00:00+01:00"`, want: bson.M{"createTime": bson.M{"$gte": createTime}}},
+		{filter: `labels.env = "prod"`, want: bson.M{"labels.env": "prod"}},
+		{filter: `labels:env`, want: bson.M{"labels.env": bson.M{"$exists": true}}},
+		{
+			filter: `name = "a" AND labels.env = "prod"`,
+			want:   bson.M{"$and": bson.A{bson.M{"name": "a"}, bson.M{"labels.env": "prod"}}},
+		},
+		{
+			filter: `name = "a" name = "b"`,
+			want:   bson.M{"$and": bson.A{bson.M{"name": "a"}, bson.M{"name": "b"}}},
+		},
+		{
+			filter: `name = "a" OR name = "b"`,
+			want:   bson.M{"$or": bson.A{bson.M{"name": "a"}, bson.M{"name": "b"}}},
+		},
+		{
+			filter: `NOT (name = "a" OR -labels:env)`,
+			want: bson.M{"$nor": bson.A{bson.M{"$or": bson.A{
+				bson.M{"name": "a"},
+				bson.M{"$nor": bson.A{bson.M{"labels.env": bson.M{"$exists": true}}}},
+			}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := Parse(tt.filter, testSchema)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := filter.BSON(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	infra := &testElement{
+		id:         "5f0d8a5e-6c1e-4f6e-9a39-3f6b0e9b9f0a",
+		name:       "infra-core",
+		labels:     map[string]string{"env": "prod"},
+		createTime: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	web := &testElement{
+		id:         "0b6d5c5a-2a4e-4c47-8f4a-7f3bd0c1a2e1",
+		name:       "web",
+		labels:     map[string]string{},
+		createTime: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Each filter is evaluated against [infra, web], the expectations follow the semantics of the BSON translation.
+	tests := []struct {
+		filter string
+		want   [2]bool
+	}{
+		{filter: ``, want: [2]bool{true, true}},
+		{filter: `name = "web"`, want: [2]bool{false, true}},
+		{filter: `name:"infra*"`, want: [2]bool{true, false}},
+		{filter: `name != "infra*"`, want: [2]bool{false, true}},
+		{filter: `id = "0b6d5c5a-2a4e-4c47-8f4a-7f3bd0c1a2e1"`, want: [2]bool{false, true}},
+		{filter: `create_time > "2026-01-01T00:00:00Z"`, want: [2]bool{true, false}},
+		{filter: `create_time <= "2025-06-01T00:00:00Z"`, want: [2]bool{false, true}},
+		{filter: `labels:env`, want: [2]bool{true, false}},
+		{filter: `labels.env = "prod"`, want: [2]bool{true, false}},
+		// Like MongoDB, a missing key only matches a negative comparison.
+		{filter: `labels.env != "prod"`, want: [2]bool{false, true}},
+		{filter: `labels.env < "z"`, want: [2]bool{true, false}},
+		{filter: `name = "web" OR labels.env = "prod"`, want: [2]bool{true, true}},
+		{filter: `name = "web" AND labels.env = "prod"`, want: [2]bool{false, false}},
+		{filter: `NOT name = "web"`, want: [2]bool{true, false}},
+		{filter: `-labels:env`, want: [2]bool{false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := Parse(tt.filter, testSchema)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			for i, e := range []*testElement{infra, web} {
+				if got := filter.Match(e.get); got != tt.want[i] {
+					t.Errorf("Match(%s) = %v, want %v", e.name, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package filtering
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenText
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenComparator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenMinus
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int // 1 based offset of the token in the filter.
+}
+
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func isTextRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.*-+", r)
+}
+
+// next returns the next token, skipping whitespace.
+// spaceBefore reports whether whitespace preceded the token, which is needed to tell a "-" negation from a value.
+func (l *lexer) next() (tok token, spaceBefore bool, err error) {
+	start := l.pos
+	for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	spaceBefore = l.pos > start || l.pos == 0
+
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF, pos: l.pos + 1}, spaceBefore, nil
+	}
+
+	pos := l.pos + 1
+	r := l.input[l.pos]
+	switch {
+	case r == '(':
+		l.pos++
+		return token{kind: tokenLParen, value: "(", pos: pos}, spaceBefore, nil
+	case r == ')':
+		l.pos++
+		return token{kind: tokenRParen, value: ")", pos: pos}, spaceBefore, nil
+	case r == '"' || r == '\'':
+		value, err := l.readString(r)
+		return token{kind: tokenString, value: value, pos: pos}, spaceBefore, err
+	case r == '=' || r == ':':
+		l.pos++
+		return token{kind: tokenComparator, value: string(r), pos: pos}, spaceBefore, nil
+	case r == '!' || r == '<' || r == '>':
+		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '=' {
+			l.pos++
+			return token{kind: tokenComparator, value: string(r) + "=", pos: pos}, spaceBefore, nil
+		}
+		if r == '!' {
+			return token{}, spaceBefore, newError(pos, "unexpected character %q", r)
+		}
+		return token{kind: tokenComparator, value: string(r), pos: pos}, spaceBefore, nil
+	case r == '-' && l.pos+1 < len(l.input) && !unicode.IsSpace(l.input[l.pos+1]) && !unicode.IsDigit(l.input[l.pos+1]):
+		l.pos++
+		return token{kind: tokenMinus, value: "-", pos: pos}, spaceBefore, nil
+	case isTextRune(r):
+		for l.pos < len(l.input) && isTextRune(l.input[l.pos]) {
+			l.pos++
+		}
+		value := string(l.input[pos-1 : l.pos])
+		switch value {
+		case "AND":
+			return token{kind: tokenAnd, value: value, pos: pos}, spaceBefore, nil
+		case "OR":
+			return token{kind: tokenOr, value: value, pos: pos}, spaceBefore, nil
+		case "NOT":
+			return token{kind: tokenNot, value: value, pos: pos}, spaceBefore, nil
+		}
+		return token{kind: tokenText, value: value, pos: pos}, spaceBefore, nil
+	}
+
+	return token{}, spaceBefore, newError(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) readString(quote rune) (string, error) {
+	start := l.pos + 1
+	l.pos++
+
+	var sb strings.Builder
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		l.pos++
+		switch r {
+		case quote:
+			return sb.String(), nil
+		case '\\':
+			if l.pos >= len(l.input) {
+				return "", newError(l.pos, "unterminated escape sequence")
+			}
+			sb.WriteRune(l.input[l.pos])
+			l.pos++
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return "", newError(start, "unterminated string")
+}
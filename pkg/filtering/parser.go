@@ -0,0 +1,248 @@
+// Package filtering implements the AIP-160 filter language (https://google.aip.dev/160).
+// Filters are parsed into an AST, type checked against a Schema and can then be translated
+// into a MongoDB query or evaluated in memory.
+package filtering
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error a syntax or type error, Pos is the 1 based offset of the offending token.
+type Error struct {
+	Pos     int
+	Message string
+}
+
+func newError(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
+
+// Expr node of the parsed filter.
+type Expr interface {
+	Pos() int
+}
+
+type (
+	// AndExpr all arguments must match.
+	AndExpr struct {
+		Args []Expr
+		pos  int
+	}
+
+	// OrExpr at least one of the arguments must match.
+	OrExpr struct {
+		Args []Expr
+		pos  int
+	}
+
+	// NotExpr negates its argument.
+	NotExpr struct {
+		Arg Expr
+		pos int
+	}
+
+	// Restriction compares a field with a value, eg: `labels.env = "prod"`.
+	Restriction struct {
+		Path       []string // Field path, eg: ["labels", "env"].
+		Comparator string   // One of = != < <= > >= :
+		Value      string
+		ValuePos   int
+		pos        int
+	}
+)
+
+func (e *AndExpr) Pos() int     { return e.pos }
+func (e *OrExpr) Pos() int      { return e.pos }
+func (e *NotExpr) Pos() int     { return e.pos }
+func (e *Restriction) Pos() int { return e.pos }
+
+type parser struct {
+	lexer       *lexer
+	tok         token
+	spaceBefore bool
+}
+
+// ParseExpr parses a filter without type checking it. An empty filter returns a nil Expr.
+func ParseExpr(filter string) (Expr, error) {
+	p := &parser{lexer: &lexer{input: []rune(filter)}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenEOF {
+		return nil, nil
+	}
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, newError(p.tok.pos, "unexpected %q", p.tok.value)
+	}
+	return expr, nil
+}
+
+func (p *parser) advance() error {
+	tok, spaceBefore, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok, p.spaceBefore = tok, spaceBefore
+	return nil
+}
+
+// expression = sequence {"AND" sequence}
+func (p *parser) parseExpression() (Expr, error) {
+	pos := p.tok.pos
+	args := make([]Expr, 0, 1)
+	for {
+		seq, err := p.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, seq)
+
+		if p.tok.kind != tokenAnd {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &AndExpr{Args: args, pos: pos}, nil
+}
+
+// sequence = factor {factor}, adjacent factors are implicitly joined with AND.
+func (p *parser) parseSequence() (Expr, error) {
+	pos := p.tok.pos
+	args := make([]Expr, 0, 1)
+	for {
+		factor, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, factor)
+
+		if !p.startsTerm() {
+			break
+		}
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &AndExpr{Args: args, pos: pos}, nil
+}
+
+// factor = term {"OR" term}
+func (p *parser) parseFactor() (Expr, error) {
+	pos := p.tok.pos
+	args := make([]Expr, 0, 1)
+	for {
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, term)
+
+		if p.tok.kind != tokenOr {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &OrExpr{Args: args, pos: pos}, nil
+}
+
+// term = ["NOT" | "-"] simple
+func (p *parser) parseTerm() (Expr, error) {
+	if p.tok.kind == tokenNot || p.tok.kind == tokenMinus {
+		pos := p.tok.pos
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		arg, err := p.parseSimple()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Arg: arg, pos: pos}, nil
+	}
+	return p.parseSimple()
+}
+
+// simple = restriction | "(" expression ")"
+func (p *parser) parseSimple() (Expr, error) {
+	if p.tok.kind == tokenLParen {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenRParen {
+			return nil, newError(p.tok.pos, "expected \")\"")
+		}
+		return expr, p.advance()
+	}
+	return p.parseRestriction()
+}
+
+// restriction = comparable comparator arg
+func (p *parser) parseRestriction() (Expr, error) {
+	switch p.tok.kind {
+	case tokenText:
+	case tokenEOF:
+		return nil, newError(p.tok.pos, "unexpected end of filter")
+	case tokenString:
+		return nil, newError(p.tok.pos, "expected a field name, free text search is not supported")
+	default:
+		return nil, newError(p.tok.pos, "unexpected %q", p.tok.value)
+	}
+
+	r := &Restriction{Path: strings.Split(p.tok.value, "."), pos: p.tok.pos}
+	for _, name := range r.Path {
+		if name == "" {
+			return nil, newError(r.pos, "invalid field %q", p.tok.value)
+		}
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokenComparator {
+		return nil, newError(p.tok.pos, "expected a comparator after %q, free text search is not supported", strings.Join(r.Path, "."))
+	}
+	r.Comparator = p.tok.value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokenText && p.tok.kind != tokenString {
+		return nil, newError(p.tok.pos, "expected a value after %q", r.Comparator)
+	}
+	r.Value, r.ValuePos = p.tok.value, p.tok.pos
+	return r, p.advance()
+}
+
+// startsTerm reports whether the current token can start a new term of an implicit AND sequence.
+func (p *parser) startsTerm() bool {
+	switch p.tok.kind {
+	case tokenText, tokenString, tokenLParen, tokenNot:
+		return true
+	case tokenMinus:
+		return p.spaceBefore
+	}
+	return false
+}
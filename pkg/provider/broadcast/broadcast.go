@@ -0,0 +1,293 @@
+// Package broadcast Broadcaster Provider.
+// Fans out in-process events to any number of subscribers, keeping a bounded history so subscribers can resume.
+package broadcast
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"learning/grpc-project-service/pkg/provider"
+)
+
+var (
+	ErrClosed       = errors.New("broadcaster closed")
+	ErrSlowConsumer = errors.New("subscriber didn't keep up with the published events")
+	ErrInvalidToken = errors.New("invalid sequence token")
+	ErrTokenExpired = errors.New("sequence token expired, events following it are no longer available")
+	ErrUnsubscribed = errors.New("subscription closed")
+)
+
+// Event a published payload. Token identifies its position in the stream and can be used to resume a subscription.
+type Event struct {
+	Sequence uint64
+	Token    string
+	Payload  interface{}
+}
+
+// Broadcaster Broadcaster Provider.
+// Events are published to a partition, eg: a tenant, each partition has its own sequence, history and subscribers,
+// so the traffic of a partition neither delays the subscribers of another one nor shows in their tokens.
+// SubscribeAll receives the events of every partition, in a sequence of its own.
+// Publish never blocks, subscribers that can't keep up are dropped with ErrSlowConsumer.
+// Closing the Broadcaster ends every subscription with ErrClosed, so streams built on top of it terminate.
+type Broadcaster struct {
+	provider.AbstractProvider
+
+	Config *Config
+
+	mu         sync.Mutex
+	epoch      []byte // Changes on every start, tokens of a previous process are rejected.
+	all        *stream
+	partitions map[string]*stream
+	closed     bool
+}
+
+// New creates a Broadcaster Provider.
+func New(config *Config) *Broadcaster {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Broadcaster{
+		Config:     config,
+		partitions: make(map[string]*stream),
+	}
+}
+
+// Init generates the epoch of the sequence tokens.
+func (b *Broadcaster) Init() error {
+	epoch := make([]byte, 16)
+	if _, err := rand.Read(epoch); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.epoch = epoch
+	b.all = b.newStream("\x00all")
+	b.partitions = make(map[string]*stream)
+	return nil
+}
+
+// Publish sends the payload to the subscribers of the partition and to those of every partition.
+// Returns the resulting event of the partition.
+func (b *Broadcaster) Publish(partition string, payload interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.all.publish(payload)
+	return b.partition(partition).publish(payload)
+}
+
+// Subscribe starts a subscription to the events of a partition. With an empty token only new events are received,
+// otherwise the events published after the token are replayed first. Tokens of other partitions are rejected.
+func (b *Broadcaster) Subscribe(partition, token string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	return b.partition(partition).subscribe(b, token)
+}
+
+// SubscribeAll is Subscribe for the events of every partition, its tokens can only be used with SubscribeAll.
+func (b *Broadcaster) SubscribeAll(token string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	return b.all.subscribe(b, token)
+}
+
+// Close ends all subscriptions.
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, s := range append(b.streams(), b.all) {
+		for sub := range s.subscribers {
+			s.unsubscribe(sub, ErrClosed)
+		}
+	}
+	return nil
+}
+
+// partition returns the stream of a partition, it is created on first use. Must be called with the lock held.
+func (b *Broadcaster) partition(name string) *stream {
+	s, ok := b.partitions[name]
+	if !ok {
+		s = b.newStream(name)
+		b.partitions[name] = s
+	}
+	return s
+}
+
+func (b *Broadcaster) streams() []*stream {
+	streams := make([]*stream, 0, len(b.partitions))
+	for _, s := range b.partitions {
+		streams = append(streams, s)
+	}
+	return streams
+}
+
+// newStream derives the token prefix of a stream from the epoch, so a token only resumes the stream that issued it.
+func (b *Broadcaster) newStream(name string) *stream {
+	mac := hmac.New(sha256.New, b.epoch)
+	mac.Write([]byte(name))
+	return &stream{
+		prefix:      hex.EncodeToString(mac.Sum(nil)[:8]),
+		historySize: b.Config.HistorySize,
+		bufferSize:  b.Config.SubscriberBuffer,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// stream is the sequence of events of a partition. Its fields are guarded by the Broadcaster lock.
+type stream struct {
+	prefix      string
+	historySize int
+	bufferSize  int
+	sequence    uint64
+	history     []Event // Ring buffer of the last historySize events.
+	next        int     // Position of the next event in history.
+	subscribers map[*Subscription]struct{}
+}
+
+func (s *stream) publish(payload interface{}) Event {
+	s.sequence++
+	event := Event{Sequence: s.sequence, Token: s.token(s.sequence), Payload: payload}
+
+	if s.historySize > 0 {
+		if len(s.history) < s.historySize {
+			s.history = append(s.history, event)
+		} else {
+			s.history[s.next] = event
+		}
+		s.next = (s.next + 1) % s.historySize
+	}
+
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			s.unsubscribe(sub, ErrSlowConsumer)
+		}
+	}
+	return event
+}
+
+func (s *stream) subscribe(b *Broadcaster, token string) (*Subscription, error) {
+	var replay []Event
+	if len(token) > 0 {
+		after, err := s.parseToken(token)
+		if err != nil {
+			return nil, err
+		}
+		if replay, err = s.since(after); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &Subscription{
+		broadcaster: b,
+		stream:      s,
+		events:      make(chan Event, s.bufferSize+len(replay)),
+	}
+	for _, event := range replay {
+		sub.events <- event
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// since returns the events of the history following the given sequence, in order.
+func (s *stream) since(after uint64) ([]Event, error) {
+	if after > s.sequence {
+		return nil, ErrInvalidToken
+	}
+	if after == s.sequence {
+		return nil, nil
+	}
+
+	oldest := s.sequence - uint64(len(s.history)) + 1
+	if len(s.history) == 0 || after+1 < oldest {
+		return nil, ErrTokenExpired
+	}
+
+	events := make([]Event, 0, s.sequence-after)
+	for i := 0; i < len(s.history); i++ {
+		event := s.history[(s.next+i)%len(s.history)]
+		if event.Sequence > after {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *stream) token(sequence uint64) string {
+	return fmt.Sprintf("%s-%d", s.prefix, sequence)
+}
+
+func (s *stream) parseToken(token string) (uint64, error) {
+	prefix, sequence, ok := strings.Cut(token, "-")
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+	if prefix != s.prefix {
+		// Issued before a restart, or by another partition: the events can't be replayed.
+		return 0, ErrTokenExpired
+	}
+	n, err := strconv.ParseUint(sequence, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return n, nil
+}
+
+// unsubscribe must be called with the Broadcaster lock held.
+func (s *stream) unsubscribe(sub *Subscription, err error) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+// Subscription receives the events of a Broadcaster until it is closed or dropped.
+type Subscription struct {
+	broadcaster *Broadcaster
+	stream      *stream
+	events      chan Event
+	err         error
+}
+
+// Events returns the channel of published events. It is closed when the subscription ends, see Err.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the subscription ended, once Events is closed.
+func (s *Subscription) Err() error {
+	s.broadcaster.mu.Lock()
+	defer s.broadcaster.mu.Unlock()
+	return s.err
+}
+
+// Close stops the subscription, it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broadcaster.mu.Lock()
+	defer s.broadcaster.mu.Unlock()
+	s.stream.unsubscribe(s, ErrUnsubscribed)
+}
@@ -0,0 +1,215 @@
+package broadcast
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func newTestBroadcaster(t *testing.T, historySize, subscriberBuffer int) *Broadcaster {
+	t.Helper()
+	b := New(&Config{HistorySize: historySize, SubscriberBuffer: subscriberBuffer})
+	if err := b.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+// receive returns the payloads queued on the subscription, without waiting for more.
+func receive(sub *Subscription) []interface{} {
+	var payloads []interface{}
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return payloads
+			}
+			payloads = append(payloads, event.Payload)
+		default:
+			return payloads
+		}
+	}
+}
+
+func TestSubscribeResume(t *testing.T) {
+	tests := []struct {
+		name  string
+		after int // Index of the event to resume after, -1 subscribes without a token.
+		want  []interface{}
+	}{
+		{name: "no token", after: -1, want: nil},
+		{name: "first", after: 0, want: []interface{}{2, 3, 4}},
+		{name: "middle", after: 2, want: []interface{}{4}},
+		{name: "last", after: 3, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBroadcaster(t, 8, 8)
+			var tokens []string
+			for i := 1; i <= 4; i++ {
+				tokens = append(tokens, b.Publish("acme", i).Token)
+			}
+			// Events of another partition are neither replayed nor counted.
+			b.Publish("globex", "other")
+
+			token := ""
+			if tt.after >= 0 {
+				token = tokens[tt.after]
+			}
+			sub, err := b.Subscribe("acme", token)
+			if err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+			defer sub.Close()
+
+			if got := receive(sub); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("replayed %v, want %v", got, tt.want)
+			}
+			b.Publish("acme", "live")
+			if got := receive(sub); fmt.Sprint(got) != "[live]" {
+				t.Errorf("received %v after the replay, want [live]", got)
+			}
+		})
+	}
+}
+
+func TestSubscribeRejectedTokens(t *testing.T) {
+	b := newTestBroadcaster(t, 2, 8)
+
+	first := b.Publish("acme", 1).Token
+	for i := 2; i <= 4; i++ {
+		b.Publish("acme", i)
+	}
+	recent := b.Publish("acme", 5).Token
+	other := b.Publish("globex", 1).Token
+
+	restarted := newTestBroadcaster(t, 2, 8)
+	restarted.Publish("acme", 1)
+
+	tests := []struct {
+		name        string
+		broadcaster *Broadcaster
+		partition   string
+		token       string
+		want        error
+	}{
+		{name: "history expired", broadcaster: b, partition: "acme", token: first, want: ErrTokenExpired},
+		{name: "other partition", broadcaster: b, partition: "acme", token: other, want: ErrTokenExpired},
+		{name: "partition token used for all", broadcaster: b, partition: "", token: recent, want: ErrTokenExpired},
+		{name: "previous process", broadcaster: restarted, partition: "acme", token: recent, want: ErrTokenExpired},
+		{name: "malformed", broadcaster: b, partition: "acme", token: "garbage", want: ErrInvalidToken},
+		{name: "future sequence", broadcaster: b, partition: "acme", token: recent[:len(recent)-1] + "9", want: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.partition == "" {
+				_, err = tt.broadcaster.SubscribeAll(tt.token)
+			} else {
+				_, err = tt.broadcaster.Subscribe(tt.partition, tt.token)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Subscribe() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlowConsumer(t *testing.T) {
+	b := newTestBroadcaster(t, 8, 2)
+
+	slow, err := b.Subscribe("acme", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	quiet, err := b.Subscribe("globex", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer quiet.Close()
+
+	var tokens []string
+	for i := 1; i <= 3; i++ {
+		tokens = append(tokens, b.Publish("acme", i).Token)
+	}
+
+	// The buffer of the slow subscriber holds 2 events, the third one drops it.
+	if got := receive(slow); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("slow subscriber received %v, want [1 2]", got)
+	}
+	if err := slow.Err(); !errors.Is(err, ErrSlowConsumer) {
+		t.Errorf("slow subscriber Err() = %v, want ErrSlowConsumer", err)
+	}
+
+	// The traffic of another partition doesn't affect the quiet subscriber.
+	if err := quiet.Err(); err != nil {
+		t.Errorf("quiet subscriber Err() = %v, want nil", err)
+	}
+	if got := b.Publish("globex", "x"); got.Sequence != 1 {
+		t.Errorf("first event of a partition has sequence %d, want 1", got.Sequence)
+	}
+	if got := receive(quiet); fmt.Sprint(got) != "[x]" {
+		t.Errorf("quiet subscriber received %v, want [x]", got)
+	}
+
+	// Resuming from the last event received replays what was dropped.
+	resumed, err := b.Subscribe("acme", tokens[1])
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer resumed.Close()
+	if got := receive(resumed); fmt.Sprint(got) != "[3]" {
+		t.Errorf("resumed subscriber received %v, want [3]", got)
+	}
+}
+
+func TestSubscribeAll(t *testing.T) {
+	b := newTestBroadcaster(t, 8, 8)
+
+	all, err := b.SubscribeAll("")
+	if err != nil {
+		t.Fatalf("SubscribeAll() error = %v", err)
+	}
+	b.Publish("acme", "a1")
+	b.Publish("globex", "g1")
+	b.Publish("acme", "a2")
+
+	var token string
+	for i := 0; i < 2; i++ {
+		token = (<-all.Events()).Token
+	}
+	all.Close()
+
+	resumed, err := b.SubscribeAll(token)
+	if err != nil {
+		t.Fatalf("SubscribeAll() error = %v", err)
+	}
+	defer resumed.Close()
+	if got := receive(resumed); fmt.Sprint(got) != "[a2]" {
+		t.Errorf("resumed subscription received %v, want [a2]", got)
+	}
+}
+
+func TestClose(t *testing.T) {
+	b := newTestBroadcaster(t, 8, 8)
+
+	sub, err := b.Subscribe("acme", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("Events() still open after Close()")
+	}
+	if err := sub.Err(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Err() = %v, want ErrClosed", err)
+	}
+	if _, err := b.Subscribe("acme", ""); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe() after Close() error = %v, want ErrClosed", err)
+	}
+}
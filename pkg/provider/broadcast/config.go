@@ -0,0 +1,43 @@
+package broadcast
+
+import (
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultHistorySize      = 1024
+	defaultSubscriberBuffer = 64
+)
+
+// Config configuration for the Broadcaster Provider.
+type Config struct {
+	HistorySize      int // Number of past events kept to resume subscriptions.
+	SubscriberBuffer int // Events queued per subscriber before it is considered too slow and dropped.
+}
+
+// NewConfigFromEnv initializes the configuration from environment variables or config file.
+func NewConfigFromEnv() *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	v.SetDefault("BROADCAST_HISTORY_SIZE", defaultHistorySize)
+	v.SetDefault("BROADCAST_SUBSCRIBER_BUFFER", defaultSubscriberBuffer)
+
+	config.LoadFromFile(v)
+
+	historySize := v.GetInt("BROADCAST_HISTORY_SIZE")
+	subscriberBuffer := v.GetInt("BROADCAST_SUBSCRIBER_BUFFER")
+
+	logrus.WithFields(logrus.Fields{
+		"historySize":      historySize,
+		"subscriberBuffer": subscriberBuffer,
+	}).Debug("Broadcaster Config Initialized")
+
+	return &Config{
+		HistorySize:      historySize,
+		SubscriberBuffer: subscriberBuffer,
+	}
+}
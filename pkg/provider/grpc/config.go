@@ -1,6 +1,8 @@
 package grpc
 
 import (
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/tlsconfig"
 	"learning/grpc-project-service/pkg/util/config"
 
 	"github.com/sirupsen/logrus"
@@ -18,6 +20,9 @@ type Config struct {
 	LogPayload     bool // Whether or not to enable logging of the payload. Should be disabled on production.
 	EnableHealth   bool // Whether or not to register the health endpoint.
 
+	Auth *auth.Config      // Authentication of the requests.
+	TLS  *tlsconfig.Config // TLS of the listener, from the GRPC_TLS_* variables. Plaintext unless enabled.
+
 	// Json proto buffer marshaller config
 	UseEnumAsInt        bool
 	DisableEmitDefaults bool
@@ -56,6 +61,8 @@ func NewConfigFromEnv() *Config {
 		LogInterceptor:      logInterceptor,
 		LogPayload:          logPayload,
 		EnableHealth:        enableHealth,
+		Auth:                auth.NewConfigFromEnv(),
+		TLS:                 tlsconfig.NewConfigFromEnv("GRPC_TLS"),
 		UseEnumAsInt:        useEnumAsInt,
 		DisableEmitDefaults: disableEmitDefaults,
 	}
@@ -1,13 +1,17 @@
 package gateway
 
 import (
+	"time"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"learning/grpc-project-service/pkg/tlsconfig"
 	"learning/grpc-project-service/pkg/util/config"
 )
 
 const (
-	defaultPort = 8080
+	defaultPort         = 8080
+	defaultSSEHeartbeat = 15 * time.Second
 )
 
 // Config configuration for the GRPC Gateway Provider.
@@ -15,6 +19,14 @@ type Config struct {
 	Enabled    bool // Whether or not to enable the gateway.
 	Port       int  // Port on which to start the HTTP service.
 	LogPayload bool // Whether or not to enable logging of the payload. Should be disabled on production.
+
+	// Server-Sent Events, used for streaming calls when the client accepts text/event-stream.
+	SSEHeartbeat  time.Duration // Interval of the comments keeping idle event streams open.
+	SSEEventField string        // Field of the streamed messages used as event type.
+	SSEIDField    string        // Field of the streamed messages used as event id, Last-Event-ID is sent back as this query parameter.
+
+	TLS       *tlsconfig.Config // TLS of the HTTP listener, from the GRPC_GATEWAY_TLS_* variables.
+	ClientTLS *tlsconfig.Config // TLS of the connection to the GRPC server, used when the server has TLS enabled. From the GRPC_GATEWAY_CLIENT_TLS_* variables.
 }
 
 // NewConfigFromEnv initializes the configuration from environment variables.
@@ -25,22 +37,40 @@ func NewConfigFromEnv() *Config {
 	v.SetDefault("GRPC_GATEWAY_ENABLED", true)
 	v.SetDefault("GRPC_GATEWAY_PORT", defaultPort)
 	v.SetDefault("GRPC_GATEWAY_LOG_PAYLOAD", false)
+	v.SetDefault("GRPC_GATEWAY_SSE_HEARTBEAT", defaultSSEHeartbeat)
+	v.SetDefault("GRPC_GATEWAY_SSE_EVENT_FIELD", "type")
+	v.SetDefault("GRPC_GATEWAY_SSE_ID_FIELD", "sequence_token")
 
 	config.LoadFromFile(v)
 
 	enabled := v.GetBool("GRPC_GATEWAY_ENABLED")
 	port := v.GetInt("GRPC_GATEWAY_PORT")
 	logPayload := v.GetBool("GRPC_GATEWAY_LOG_PAYLOAD")
+	sseHeartbeat := v.GetDuration("GRPC_GATEWAY_SSE_HEARTBEAT")
+	sseEventField := v.GetString("GRPC_GATEWAY_SSE_EVENT_FIELD")
+	sseIDField := v.GetString("GRPC_GATEWAY_SSE_ID_FIELD")
 
 	logrus.WithFields(logrus.Fields{
-		"enabled":    enabled,
-		"port":       port,
-		"logPayload": logPayload,
+		"enabled":      enabled,
+		"port":         port,
+		"logPayload":   logPayload,
+		"sseHeartbeat": sseHeartbeat,
 	}).Debug("Gateway Config Initialized")
 
+	// The server is dialed on its listener address, which isn't a name its certificate is expected to have.
+	clientTLS := tlsconfig.NewConfigFromEnv("GRPC_GATEWAY_CLIENT_TLS")
+	if len(clientTLS.ServerName) == 0 {
+		clientTLS.ServerName = "localhost"
+	}
+
 	return &Config{
-		Enabled:    enabled,
-		Port:       port,
-		LogPayload: logPayload,
+		Enabled:       enabled,
+		Port:          port,
+		LogPayload:    logPayload,
+		SSEHeartbeat:  sseHeartbeat,
+		SSEEventField: sseEventField,
+		SSEIDField:    sseIDField,
+		TLS:           tlsconfig.NewConfigFromEnv("GRPC_GATEWAY_TLS"),
+		ClientTLS:     clientTLS,
 	}
 }
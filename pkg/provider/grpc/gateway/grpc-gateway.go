@@ -15,8 +15,11 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"learning/grpc-project-service/pkg/provider"
 	grpcProvider "learning/grpc-project-service/pkg/provider/grpc"
+	"learning/grpc-project-service/pkg/ratelimit"
+	"learning/grpc-project-service/pkg/tlsconfig"
 )
 
 // Gateway grpc Gateway Provider.
@@ -26,6 +29,7 @@ type Gateway struct {
 	provider.AbstractRunProvider
 
 	Config      *Config
+	RateLimiter *ratelimit.Limiter // Limits the requests of each client address before they are forwarded, none when nil.
 	grpcSrv     *grpcProvider.Server
 	appProvider *app.App
 
@@ -89,16 +93,31 @@ func (p *Gateway) Run() error {
 		grpc_logrus.StreamClientInterceptor(logEntry, opts...),
 	}
 
+	if p.RateLimiter != nil && p.RateLimiter.Config.Enabled {
+		unaryInterceptors = append(unaryInterceptors, rateLimitUnaryInterceptor(p.RateLimiter))
+		streamInterceptors = append(streamInterceptors, rateLimitStreamInterceptor(p.RateLimiter))
+	}
+
 	// Payload is only logged by the server if it was configured to do so.
 	if p.Config.LogPayload {
 		unaryInterceptors = append(unaryInterceptors, grpc_logrus.PayloadUnaryClientInterceptor(logEntry, p.logDeciderFunc))
 		streamInterceptors = append(streamInterceptors, grpc_logrus.PayloadStreamClientInterceptor(logEntry, p.logDeciderFunc))
 	}
 
+	transportCreds := grpc.WithInsecure()
+	if tls := p.grpcSrv.Config.TLS; tls != nil && tls.Enabled {
+		reloader, err := tlsconfig.NewReloader(p.Config.ClientTLS)
+		if err != nil {
+			logEntry.WithError(err).Error("GRPC Gateway client TLS config is invalid")
+			return err
+		}
+		transportCreds = grpc.WithTransportCredentials(credentials.NewTLS(reloader.ClientConfig()))
+	}
+
 	conn, err := grpc.DialContext(
 		context.Background(),
 		serverAddr,
-		grpc.WithInsecure(),
+		transportCreds,
 		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(unaryInterceptors...)),
 		grpc.WithStreamInterceptor(grpc_middleware.ChainStreamClient(streamInterceptors...)),
 	)
@@ -107,23 +126,33 @@ func (p *Gateway) Run() error {
 		return err
 	}
 
-	p.mux = runtime.NewServeMux(
-		runtime.WithMarshalerOption("application/x-www-form-urlencoded", &jsonPbMarshaller.JSONPb),
-		runtime.WithMarshalerOption(runtime.MIMEWildcard, &jsonPbMarshaller.JSONPb),
-		runtime.WithErrorHandler(HTTPError),
-		runtime.WithIncomingHeaderMatcher(isIncomingHeaderAllowed),
-	)
+	p.mux = newServeMux(p.Config, &jsonPbMarshaller.JSONPb)
 
 	p.client = conn
-	p.srv = &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		p.mux.ServeHTTP(w, r)
-	})}
-	//p.srv = &http.Server{Addr: addr, Handler: GitHubHandler}
+	})
+	if p.RateLimiter != nil && p.RateLimiter.Config.Enabled {
+		handler = withRemoteAddr(handler)
+	}
+	p.srv = &http.Server{Addr: addr, Handler: eventStreamHandler(handler, p.Config.SSEIDField, p.Config.SSEHeartbeat)}
+
+	serve := p.srv.ListenAndServe
+	if p.Config.TLS != nil && p.Config.TLS.Enabled {
+		reloader, err := tlsconfig.NewReloader(p.Config.TLS)
+		if err != nil {
+			logEntry.WithError(err).Error("GRPC Gateway TLS config is invalid")
+			return err
+		}
+		p.srv.TLSConfig = reloader.ServerConfig()
+		// The certificate comes from the TLS config, so it can be reloaded.
+		serve = func() error { return p.srv.ListenAndServeTLS("", "") }
+	}
 
 	p.SetRunning(true)
 
 	logEntry.Info("GRPC Gateway Provider launched")
-	if err := p.srv.ListenAndServe(); err != http.ErrServerClosed {
+	if err := serve(); err != http.ErrServerClosed {
 		logEntry.WithError(err).Error("GRPC Gateway Provider launch failed")
 		return err
 	}
@@ -131,6 +160,23 @@ func (p *Gateway) Run() error {
 	return nil
 }
 
+// newServeMux creates the ServeMux of the gateway, JSON being the default format and text/event-stream the one of streams.
+func newServeMux(config *Config, jsonPb *runtime.JSONPb) *runtime.ServeMux {
+	return runtime.NewServeMux(
+		runtime.WithMarshalerOption("application/x-www-form-urlencoded", jsonPb),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, jsonPb),
+		runtime.WithMarshalerOption(eventStreamMIME, &EventStreamMarshaler{
+			Marshaler:  jsonPb,
+			EventField: config.SSEEventField,
+			IDField:    config.SSEIDField,
+		}),
+		runtime.WithForwardResponseOption(establishEventStream),
+		runtime.WithErrorHandler(HTTPError),
+		runtime.WithIncomingHeaderMatcher(isIncomingHeaderAllowed),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+	)
+}
+
 // RegisterServices used to register the grpc providers.
 // The Gateway isn't able to use the same reflection based functionality as the GRPC Provider, therefor this is needed.
 func (p *Gateway) RegisterServices(functions ...func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error) error {
@@ -185,12 +231,37 @@ var allowedIncomingHeaders = map[string]struct{}{
 	"X-Github-Hook-Installation-Target-Type": {},
 	"X-Hub-Signature-256":                    {},
 	"Oc-Qa-Key":                              {},
+	"If-Match":                               {},
+	"Idempotency-Key":                        {},
+	"X-Api-Key":                              {},
+	"Accept-Language":                        {},
+	"X-Tenant-Id":                            {},
 }
 
 func isIncomingHeaderAllowed(s string) (string, bool) {
+	// Only the gateway marks the requests it rate limited, clients can't.
+	if strings.EqualFold(s, runtime.MetadataHeaderPrefix+ratelimit.GatewayMetadataKey) {
+		return "", false
+	}
 	if _, isAllowed := allowedIncomingHeaders[s]; isAllowed {
 		return strings.ToLower(s), true
 	}
 
 	return runtime.DefaultHeaderMatcher(s)
 }
+
+// outgoingHeaders metadata sent by the GRPC server that is exposed as standard HTTP headers, indexed by metadata key.
+var outgoingHeaders = map[string]string{
+	"etag":                "ETag",
+	"ratelimit-limit":     "RateLimit-Limit",
+	"ratelimit-remaining": "RateLimit-Remaining",
+	"ratelimit-reset":     "RateLimit-Reset",
+}
+
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if header, ok := outgoingHeaders[key]; ok {
+		return header, true
+	}
+
+	return runtime.MetadataHeaderPrefix + key, true
+}
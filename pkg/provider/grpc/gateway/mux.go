@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/textproto"
+	"strconv"
 	"strings"
 
 	"github.com/golang/protobuf/ptypes"
@@ -15,6 +17,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/grpclog"
 	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/pkg/util"
 )
 
 // MuxWrapper A wrapper around the GRPC Gateway ServeMux.
@@ -88,6 +91,7 @@ func HTTPError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Mar
 		Code:    int32(s.Code()),
 	}
 	details := make([]interface{}, 0)
+	preconditionFailed := false
 	for _, detail := range s.Proto().GetDetails() {
 		switch detail.GetTypeUrl() {
 		case "type.googleapis.com/google.rpc.ErrorInfo":
@@ -117,6 +121,39 @@ func HTTPError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Mar
 					Type string `json:"@type"`
 				}{d, detail.GetTypeUrl()})
 			}
+		case "type.googleapis.com/google.rpc.PreconditionFailure":
+			d := new(errdetails.PreconditionFailure)
+			err = ptypes.UnmarshalAny(detail, d)
+			if err == nil {
+				details = append(details, struct {
+					*errdetails.PreconditionFailure
+					Type string `json:"@type"`
+				}{d, detail.GetTypeUrl()})
+				for _, violation := range d.GetViolations() {
+					preconditionFailed = preconditionFailed || violation.GetType() == util.PreconditionETag
+				}
+			}
+		case "type.googleapis.com/google.rpc.RetryInfo":
+			d := new(errdetails.RetryInfo)
+			err = ptypes.UnmarshalAny(detail, d)
+			if err == nil {
+				details = append(details, struct {
+					*errdetails.RetryInfo
+					Type string `json:"@type"`
+				}{d, detail.GetTypeUrl()})
+				// See RFC 9110 section 10.2.3.
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.GetRetryDelay().AsDuration().Seconds()))))
+			}
+		case "type.googleapis.com/google.rpc.LocalizedMessage":
+			d := new(errdetails.LocalizedMessage)
+			err = ptypes.UnmarshalAny(detail, d)
+			if err == nil {
+				details = append(details, struct {
+					*errdetails.LocalizedMessage
+					Type string `json:"@type"`
+				}{d, detail.GetTypeUrl()})
+				w.Header().Set("Content-Language", d.GetLocale())
+			}
 		case "type.googleapis.com/google.rpc.QuotaFailure":
 			d := new(errdetails.QuotaFailure)
 			err = ptypes.UnmarshalAny(detail, d)
@@ -160,6 +197,11 @@ func HTTPError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Mar
 	}
 
 	st := runtime.HTTPStatusFromCode(s.Code())
+	if s.Code() == codes.FailedPrecondition && preconditionFailed {
+		// A failed If-Match or etag check, see RFC 7232 section 4.2. Other preconditions are about the state of
+		// resources, not about conditional headers, they stay 400.
+		st = http.StatusPreconditionFailed
+	}
 	w.WriteHeader(st)
 	if _, err := w.Write(buf); err != nil {
 		grpclog.Infof("Failed to write response: %v", err)
@@ -172,9 +214,9 @@ func HTTPError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Mar
 
 func handleForwardResponseServerMetadata(w http.ResponseWriter, mux *runtime.ServeMux, md runtime.ServerMetadata) {
 	for k, vs := range md.HeaderMD {
-		if strings.HasPrefix(k, "Grpc-Metadata-") {
+		if h, ok := outgoingHeaderMatcher(k); ok {
 			for _, v := range vs {
-				w.Header().Add(strings.TrimLeft(k, "Grpc-Metadata-"), v)
+				w.Header().Add(h, v)
 			}
 		}
 	}
@@ -0,0 +1,90 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"learning/grpc-project-service/pkg/ratelimit"
+)
+
+type remoteAddrKey struct{}
+
+// withRemoteAddr keeps the address of the client in the context of the request, for the rate limiting interceptors.
+func withRemoteAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), remoteAddrKey{}, r.RemoteAddr)))
+	})
+}
+
+// allowClient counts a request forwarded to the given method against the address of its client, false when it isn't limited.
+func allowClient(ctx context.Context, limiter *ratelimit.Limiter, method string) (ratelimit.Decision, bool) {
+	addr, ok := ctx.Value(remoteAddrKey{}).(string)
+	if !ok {
+		return ratelimit.Decision{}, false
+	}
+	return limiter.AllowAddress(addr, method)
+}
+
+// rateLimitUnaryInterceptor rejects the requests of the clients over their limit before they are forwarded to the GRPC server,
+// which only sees the address of the gateway. Authenticated callers are also limited by the server, by their identity.
+func rateLimitUnaryInterceptor(limiter *ratelimit.Limiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		decision, ok := allowClient(ctx, limiter, method)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		err := decision.Err()
+		if err == nil {
+			err = invoker(limiter.GatewayContext(ctx), method, req, reply, cc, opts...)
+		}
+		// The headers of the response are the ones requested by the gateway handler.
+		for _, opt := range opts {
+			if header, ok := opt.(grpc.HeaderCallOption); ok {
+				*header.HeaderAddr = withRateLimit(*header.HeaderAddr, decision.Metadata())
+			}
+		}
+		return err
+	}
+}
+
+// rateLimitStreamInterceptor is the streaming counterpart of rateLimitUnaryInterceptor, streams are counted once, when they are opened.
+func rateLimitStreamInterceptor(limiter *ratelimit.Limiter) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		decision, ok := allowClient(ctx, limiter, method)
+		if !ok {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		if err := decision.Err(); err != nil {
+			return nil, err
+		}
+
+		stream, err := streamer(limiter.GatewayContext(ctx), desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &rateLimitedStream{ClientStream: stream, md: decision.Metadata()}, nil
+	}
+}
+
+// rateLimitedStream adds the ratelimit-* metadata of the gateway to the headers of the stream.
+type rateLimitedStream struct {
+	grpc.ClientStream
+	md metadata.MD
+}
+
+func (s *rateLimitedStream) Header() (metadata.MD, error) {
+	header, err := s.ClientStream.Header()
+	return withRateLimit(header, s.md), err
+}
+
+// withRateLimit adds the ratelimit-* metadata of the gateway to the headers sent by the server, unless the server set its own.
+// The limit of the identity of the caller is more relevant than the one of its address.
+func withRateLimit(header, gateway metadata.MD) metadata.MD {
+	if len(header.Get("ratelimit-limit")) > 0 {
+		return header
+	}
+	return metadata.Join(header, gateway)
+}
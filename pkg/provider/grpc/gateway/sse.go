@@ -0,0 +1,239 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const eventStreamMIME = "text/event-stream"
+
+// EventStreamMarshaler writes the messages of streaming calls as Server-Sent Events, so browsers can consume them with EventSource.
+// Each message becomes an `event:`/`id:`/`data:` frame, the data being the JSON of the message.
+// Stream errors are sent as an "error" event. Requests are still decoded with the embedded Marshaler.
+type EventStreamMarshaler struct {
+	runtime.Marshaler
+
+	EventField string // Enum or string field of the messages used as event type, eg: "type".
+	IDField    string // String field of the messages used as event id, eg: "sequence_token".
+}
+
+// ContentType always returns text/event-stream.
+func (m *EventStreamMarshaler) ContentType(_ interface{}) string {
+	return eventStreamMIME
+}
+
+// Delimiter is empty, frames are terminated by Marshal.
+func (m *EventStreamMarshaler) Delimiter() []byte {
+	return nil
+}
+
+// Marshal returns the SSE frame of a streamed chunk, eg: {"result": message} or {"error": status}.
+func (m *EventStreamMarshaler) Marshal(v interface{}) ([]byte, error) {
+	event, value := "", v
+	switch chunk := v.(type) {
+	case map[string]interface{}:
+		if result, ok := chunk["result"]; ok {
+			value = result
+		}
+	case map[string]proto.Message:
+		if st, ok := chunk["error"]; ok {
+			event, value = "error", st
+		}
+	case *Error:
+		event = "error"
+	}
+
+	data, err := m.Marshaler.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	// Frames are line based, the data must fit on a single line.
+	compact := new(bytes.Buffer)
+	if err := json.Compact(compact, data); err != nil {
+		return nil, err
+	}
+
+	var id string
+	if msg, ok := value.(proto.Message); ok && len(event) == 0 {
+		event = m.fieldValue(msg, m.EventField)
+		id = m.fieldValue(msg, m.IDField)
+	}
+
+	frame := new(bytes.Buffer)
+	if len(event) > 0 {
+		fmt.Fprintf(frame, "event: %s\n", event)
+	}
+	if len(id) > 0 {
+		fmt.Fprintf(frame, "id: %s\n", id)
+	}
+	fmt.Fprintf(frame, "data: %s\n\n", compact.Bytes())
+	return frame.Bytes(), nil
+}
+
+// fieldValue returns the string or enum value name of a top level field, empty when the message doesn't have it.
+func (m *EventStreamMarshaler) fieldValue(msg proto.Message, name string) string {
+	if len(name) == 0 {
+		return ""
+	}
+	r := msg.ProtoReflect()
+	fd := r.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil || fd.IsList() || fd.IsMap() {
+		return ""
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return strings.ReplaceAll(r.Get(fd).String(), "\n", " ")
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByNumber(r.Get(fd).Enum()); value != nil {
+			return string(value.Name())
+		}
+	}
+	return ""
+}
+
+// eventStreamHandler prepares requests accepting text/event-stream for the EventStreamMarshaler.
+// Last-Event-ID is turned into the idParam query parameter so reconnecting EventSources resume where they stopped,
+// and comments are sent every heartbeat so proxies don't close idle streams.
+func eventStreamHandler(next http.Handler, idParam string, heartbeat time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != eventStreamMIME {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if lastEventID := r.Header.Get("Last-Event-ID"); len(lastEventID) > 0 && len(idParam) > 0 {
+			query := r.URL.Query()
+			if !query.Has(idParam) {
+				query.Set(idParam, lastEventID)
+				r.URL.RawQuery = query.Encode()
+			}
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+
+		sw := &eventStreamWriter{ResponseWriter: w, flusher: flusher}
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			sw.keepAlive(heartbeat, done)
+		}()
+
+		next.ServeHTTP(sw, r)
+
+		close(done)
+		<-stopped
+	})
+}
+
+// eventStreamWriter serializes the writes of the gateway and of the heartbeat.
+type eventStreamWriter struct {
+	http.ResponseWriter
+
+	mu      sync.Mutex
+	flusher http.Flusher
+	ready   bool // Set once the gRPC stream is established, heartbeats are only sent from then on.
+	written bool // Set once the response headers are written.
+}
+
+// Header returns a detached map once the stream is established, the response headers are final by then
+// and the heartbeat may be writing them concurrently.
+func (w *eventStreamWriter) Header() http.Header {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ready {
+		return http.Header{}
+	}
+	return w.ResponseWriter.Header()
+}
+
+func (w *eventStreamWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteHeader ignores the status of errors ending a stream after a heartbeat, they are sent as an error event instead.
+func (w *eventStreamWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.written {
+		return
+	}
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *eventStreamWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flusher.Flush()
+}
+
+// establish is called once the gRPC stream is established.
+// The response headers are only written with the first event or heartbeat, so errors returned by the server
+// right away, eg: an expired sequence token, are still reported with a proper HTTP status.
+func (w *eventStreamWriter) establish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ResponseWriter.Header().Set("Content-Type", eventStreamMIME)
+	w.ready = true
+}
+
+func (w *eventStreamWriter) keepAlive(heartbeat time.Duration, done <-chan struct{}) {
+	if heartbeat <= 0 {
+		return
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			w.heartbeat()
+		}
+	}
+}
+
+func (w *eventStreamWriter) heartbeat() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.ready {
+		return
+	}
+	w.written = true
+	if _, err := w.ResponseWriter.Write([]byte(": heartbeat\n\n")); err != nil {
+		grpclog.Infof("Failed to send heartbeat: %v", err)
+		return
+	}
+	w.flusher.Flush()
+}
+
+// establishEventStream is a ForwardResponseOption, the gateway calls it with a nil message once a streaming call is established.
+func establishEventStream(_ context.Context, w http.ResponseWriter, resp proto.Message) error {
+	if sw, ok := w.(*eventStreamWriter); ok && resp == nil {
+		sw.establish()
+	}
+	return nil
+}
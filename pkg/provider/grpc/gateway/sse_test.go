@@ -0,0 +1,223 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	pb "learning/grpc-project-service/api/gen/go/platform/v1"
+)
+
+// watchServer streams two events following the sequence token of the request, tokens being "tok-<n>".
+type watchServer struct {
+	pb.UnimplementedProjectAPIServer
+	tokens chan string // Sequence token of each WatchProjects call.
+}
+
+func (s *watchServer) WatchProjects(req *pb.WatchProjectsRequest, stream pb.ProjectAPI_WatchProjectsServer) error {
+	s.tokens <- req.SequenceToken
+	if req.SequenceToken == "tok-expired" {
+		return status.Error(codes.OutOfRange, "sequence token expired")
+	}
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	next := 1
+	if req.SequenceToken == "tok-1" {
+		next = 2
+	}
+	for _, eventType := range []pb.WatchProjectsResponse_EventType{pb.WatchProjectsResponse_CREATED, pb.WatchProjectsResponse_UPDATED} {
+		if err := stream.Send(&pb.WatchProjectsResponse{
+			Type:          eventType,
+			Project:       &pb.Project{Name: "infra\ncore"},
+			SequenceToken: "tok-" + strconv.Itoa(next),
+		}); err != nil {
+			return err
+		}
+		next++
+	}
+	return nil
+}
+
+// newEventStreamServer serves the gateway of a watchServer, configured as Run does.
+func newEventStreamServer(t *testing.T) (*httptest.Server, *watchServer) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	watch := &watchServer{tokens: make(chan string, 8)}
+	grpcServer := grpc.NewServer()
+	pb.RegisterProjectAPIServer(grpcServer, watch)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	config := &Config{SSEEventField: "type", SSEIDField: "sequence_token"}
+	// Indented like the service's marshaller, frames must still hold the data on a single line.
+	mux := newServeMux(config, &runtime.JSONPb{MarshalOptions: protojson.MarshalOptions{Indent: " ", UseProtoNames: true}})
+	if err := pb.RegisterProjectAPIHandler(context.Background(), mux, conn); err != nil {
+		t.Fatalf("RegisterProjectAPIHandler() error = %v", err)
+	}
+
+	srv := httptest.NewServer(eventStreamHandler(mux, config.SSEIDField, 0))
+	t.Cleanup(srv.Close)
+	return srv, watch
+}
+
+// sseFrame is a parsed Server-Sent Event.
+type sseFrame struct {
+	event string
+	id    string
+	data  string
+}
+
+// readFrames parses the frames of an event stream, checking each one is terminated by a blank line.
+func readFrames(t *testing.T, resp *http.Response) []sseFrame {
+	t.Helper()
+	var frames []sseFrame
+	var frame sseFrame
+	open := false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			frames = append(frames, frame)
+			frame, open = sseFrame{}, false
+			continue
+		}
+		open = true
+		field, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			t.Fatalf("malformed line %q", line)
+		}
+		switch field {
+		case "event":
+			frame.event = value
+		case "id":
+			frame.id = value
+		case "data":
+			if frame.data != "" {
+				t.Fatalf("frame with more than one data line: %q", line)
+			}
+			frame.data = value
+		default:
+			t.Fatalf("unexpected field %q", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading the stream: %v", err)
+	}
+	if open {
+		t.Fatalf("last frame %+v isn't terminated by a blank line", frame)
+	}
+	return frames
+}
+
+func TestEventStream(t *testing.T) {
+	srv, watch := newEventStreamServer(t)
+
+	tests := []struct {
+		name        string
+		query       string
+		lastEventID string
+		wantToken   string
+		wantIDs     []string
+	}{
+		{name: "new stream", wantToken: "", wantIDs: []string{"tok-1", "tok-2"}},
+		{name: "Last-Event-ID resume", lastEventID: "tok-1", wantToken: "tok-1", wantIDs: []string{"tok-2", "tok-3"}},
+		{name: "query parameter wins", query: "?sequence_token=tok-1", lastEventID: "tok-9", wantToken: "tok-1", wantIDs: []string{"tok-2", "tok-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/projects:watch"+tt.query, nil)
+			req.Header.Set("Accept", eventStreamMIME)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != eventStreamMIME {
+				t.Errorf("Content-Type = %q, want %q", got, eventStreamMIME)
+			}
+			if got := resp.Header.Get("Cache-Control"); got != "no-cache" {
+				t.Errorf("Cache-Control = %q, want no-cache", got)
+			}
+
+			frames := readFrames(t, resp)
+			if got := <-watch.tokens; got != tt.wantToken {
+				t.Errorf("server received sequence_token %q, want %q", got, tt.wantToken)
+			}
+			if len(frames) != len(tt.wantIDs) {
+				t.Fatalf("received %d frames, want %d: %+v", len(frames), len(tt.wantIDs), frames)
+			}
+			for i, frame := range frames {
+				if frame.id != tt.wantIDs[i] {
+					t.Errorf("frame %d id = %q, want %q", i, frame.id, tt.wantIDs[i])
+				}
+				if want := []string{"CREATED", "UPDATED"}[i]; frame.event != want {
+					t.Errorf("frame %d event = %q, want %q", i, frame.event, want)
+				}
+				var data struct {
+					SequenceToken string `json:"sequence_token"`
+					Project       struct {
+						Name string `json:"name"`
+					} `json:"project"`
+				}
+				if err := json.Unmarshal([]byte(frame.data), &data); err != nil {
+					t.Fatalf("frame %d data %q isn't JSON: %v", i, frame.data, err)
+				}
+				if data.SequenceToken != frame.id || data.Project.Name != "infra\ncore" {
+					t.Errorf("frame %d data = %+v", i, data)
+				}
+			}
+		})
+	}
+}
+
+func TestEventStreamError(t *testing.T) {
+	srv, watch := newEventStreamServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/projects:watch", nil)
+	req.Header.Set("Accept", eventStreamMIME)
+	req.Header.Set("Last-Event-ID", "tok-expired")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	<-watch.tokens
+
+	// Errors returned before the first event keep their HTTP status.
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.StatusCode)
+	}
+}
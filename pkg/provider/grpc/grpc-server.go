@@ -18,11 +18,14 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/pkg/auth"
 	"learning/grpc-project-service/pkg/provider"
+	"learning/grpc-project-service/pkg/tlsconfig"
 )
 
 // CustomOpts when create a grpc server, you can custom yourself interceptor.
@@ -39,10 +42,11 @@ type CustomOpts struct {
 type Server struct {
 	provider.AbstractRunProvider
 
-	Config   *Config
-	Listener net.Listener
-	Server   *grpc.Server
-	Opts     []CustomOpts
+	Config        *Config
+	Listener      net.Listener
+	Server        *grpc.Server
+	Opts          []CustomOpts
+	Authenticator *auth.Authenticator
 }
 
 func recoverHandler(ctx context.Context, p interface{}) (err error) {
@@ -58,13 +62,18 @@ func New(config *Config, customOpts ...CustomOpts) *Server {
 	}
 
 	return &Server{
-		Config: config,
-		Opts:   customOpts,
+		Config:        config,
+		Opts:          customOpts,
+		Authenticator: auth.NewAuthenticator(config.Auth),
 	}
 }
 
 // Init creates the grpc server (doesn't start it yet) and adds useful interceptors.
 func (p *Server) Init() error {
+	if err := p.Authenticator.Init(); err != nil {
+		return err
+	}
+
 	logger := logrus.NewEntry(logrus.StandardLogger())
 
 	grpc_logrus.JsonPbMarshaller = NewJsonPbMarshaller()
@@ -105,6 +114,13 @@ func (p *Server) Init() error {
 	}
 
 	var serverOpts []grpc.ServerOption
+	if p.Config.TLS != nil && p.Config.TLS.Enabled {
+		reloader, err := tlsconfig.NewReloader(p.Config.TLS)
+		if err != nil {
+			return err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(reloader.ServerConfig())))
+	}
 	for _, opt := range p.Opts {
 		unaryInterceptors = append(unaryInterceptors, opt.UnaryInterceptor...)
 		streamInterceptors = append(streamInterceptors, opt.StreamInterceptor...)
@@ -155,9 +171,9 @@ func (p *Server) Close() error {
 	return p.AbstractRunProvider.Close()
 }
 
+// authFunc validates the bearer token of the request, see auth.Authenticator.
 func (p *Server) authFunc(ctx context.Context) (context.Context, error) {
-	// TODO: Add support for authentication.
-	return ctx, nil
+	return p.Authenticator.AuthFunc(ctx)
 }
 
 func (p *Server) logDeciderFunc(ctx context.Context, fullMethodName string, servingObject interface{}) bool {
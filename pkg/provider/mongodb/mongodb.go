@@ -0,0 +1,77 @@
+// Package mongodb MongoDB Provider.
+// Maintains the connection to a MongoDB deployment.
+package mongodb
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"learning/grpc-project-service/pkg/provider"
+)
+
+// MongoDB MongoDB Provider.
+// Connects to the configured deployment on Init and disconnects on Close.
+type MongoDB struct {
+	provider.AbstractProvider
+
+	Config   *Config
+	Client   *mongo.Client
+	Database *mongo.Database
+}
+
+// New creates a MongoDB Provider.
+func New(config *Config) *MongoDB {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &MongoDB{
+		Config: config,
+	}
+}
+
+// Init connects to MongoDB and makes sure the deployment is reachable.
+func (p *MongoDB) Init() error {
+	logEntry := logrus.WithField("database", p.Config.Database)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Config.ConnectTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(p.Config.URI))
+	if err != nil {
+		logEntry.WithError(err).Error("MongoDB Provider could not connect")
+		return err
+	}
+
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		logEntry.WithError(err).Error("MongoDB Provider could not ping the deployment")
+		_ = client.Disconnect(context.Background())
+		return err
+	}
+
+	p.Client = client
+	p.Database = client.Database(p.Config.Database)
+
+	logEntry.Info("MongoDB Provider initialized")
+	return nil
+}
+
+// Close disconnects from MongoDB.
+func (p *MongoDB) Close() error {
+	if p.Client == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Config.ConnectTimeout)
+	defer cancel()
+	if err := p.Client.Disconnect(ctx); err != nil {
+		logrus.WithError(err).Error("Error while closing MongoDB connection")
+		return err
+	}
+
+	return nil
+}
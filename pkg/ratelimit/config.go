@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultRate        = 10
+	defaultBurst       = 20
+	defaultIdleTimeout = 10 * time.Minute
+	// Probes would otherwise share the budget of the address they come from.
+	defaultExemptMethods = "/grpc.health.v1.Health/"
+)
+
+// Config configuration of the rate Limiter.
+type Config struct {
+	Enabled     bool          // Whether or not requests are rate limited.
+	Rate        float64       // Requests per second allowed to each caller, by default.
+	Burst       int           // Requests a caller may send at once, by default.
+	IdleTimeout time.Duration // How long the bucket of an idle caller is kept.
+
+	// Limits of specific methods, as comma separated method=rate:burst entries, eg: /platform.v1.ProjectAPI/CreateProject=1:5.
+	// A method ending with / matches all methods of the service, see util.MatchMethod. The first matching entry applies.
+	MethodLimits string
+	// Full gRPC method names that aren't limited, a name ending with / matches all methods of the service, see util.MatchMethod.
+	ExemptMethods []string
+}
+
+// NewConfigFromEnv initializes the configuration from environment variables or config file.
+func NewConfigFromEnv() *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	v.SetDefault("RATELIMIT_ENABLED", false)
+	v.SetDefault("RATELIMIT_RATE", defaultRate)
+	v.SetDefault("RATELIMIT_BURST", defaultBurst)
+	v.SetDefault("RATELIMIT_IDLE_TIMEOUT", defaultIdleTimeout)
+	v.SetDefault("RATELIMIT_METHOD_LIMITS", "")
+	v.SetDefault("RATELIMIT_EXEMPT_METHODS", defaultExemptMethods)
+
+	config.LoadFromFile(v)
+
+	enabled := v.GetBool("RATELIMIT_ENABLED")
+	r := v.GetFloat64("RATELIMIT_RATE")
+	burst := v.GetInt("RATELIMIT_BURST")
+	idleTimeout := v.GetDuration("RATELIMIT_IDLE_TIMEOUT")
+	methodLimits := v.GetString("RATELIMIT_METHOD_LIMITS")
+
+	exemptMethods := make([]string, 0)
+	for _, method := range strings.Split(v.GetString("RATELIMIT_EXEMPT_METHODS"), ",") {
+		if method = strings.TrimSpace(method); len(method) > 0 {
+			exemptMethods = append(exemptMethods, method)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"enabled":      enabled,
+		"rate":         r,
+		"burst":        burst,
+		"methodLimits": methodLimits,
+	}).Debug("Rate Limit Config Initialized")
+
+	return &Config{
+		Enabled:       enabled,
+		Rate:          r,
+		Burst:         burst,
+		IdleTimeout:   idleTimeout,
+		MethodLimits:  methodLimits,
+		ExemptMethods: exemptMethods,
+	}
+}
+
+// methodLimit limit of the methods matching Method.
+type methodLimit struct {
+	Method string
+	Rate   float64
+	Burst  int
+}
+
+// parseMethodLimits parses the Config.MethodLimits entries.
+func parseMethodLimits(value string) ([]methodLimit, error) {
+	limits := make([]methodLimit, 0)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); len(entry) == 0 {
+			continue
+		}
+
+		method, limit, ok := strings.Cut(entry, "=")
+		r, b, ok2 := strings.Cut(limit, ":")
+		if !ok || !ok2 || !strings.HasPrefix(method, "/") {
+			return nil, fmt.Errorf("invalid rate limit %q, expected /package.Service/Method=rate:burst", entry)
+		}
+		rate, err := strconv.ParseFloat(r, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid rate of %q", entry)
+		}
+		burst, err := strconv.Atoi(b)
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("invalid burst of %q", entry)
+		}
+		limits = append(limits, methodLimit{Method: method, Rate: rate, Burst: burst})
+	}
+	return limits, nil
+}
@@ -0,0 +1,268 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/provider"
+	grpcProvider "learning/grpc-project-service/pkg/provider/grpc"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// Decision outcome of a request, with the state of the bucket it was counted in.
+type Decision struct {
+	Allowed    bool
+	Limit      int           // Size of the bucket.
+	Remaining  int           // Requests that can still be sent at once.
+	Reset      time.Duration // Until the bucket is full again.
+	RetryAfter time.Duration // Until the next request is allowed, zero when allowed.
+}
+
+// Metadata returns the decision as the ratelimit-* metadata, the gateway sends them as the RateLimit-* headers.
+func (d Decision) Metadata() metadata.MD {
+	return metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(d.Limit),
+		"ratelimit-remaining", strconv.Itoa(d.Remaining),
+		"ratelimit-reset", strconv.Itoa(ceilSeconds(d.Reset)),
+	)
+}
+
+// Err returns the error of a rejected request, nil when it was allowed.
+func (d Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	return util.RateLimited(d.RetryAfter, fmt.Sprintf("rate limit exceeded, retry in %ds", ceilSeconds(d.RetryAfter)))
+}
+
+// GatewayMetadataKey metadata marking the requests forwarded by the gateway once it limited them by the address of their client.
+// Its value is a secret of the Limiter, the gateway must not forward it from the HTTP requests.
+const GatewayMetadataKey = "x-ratelimit-gateway"
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter limits the rate of the requests of each caller with token buckets.
+// Callers are told apart by their API key, their subject, or else their address.
+type Limiter struct {
+	provider.AbstractProvider
+
+	Config       *Config
+	methods      []methodLimit
+	gatewayToken string // Value of GatewayMetadataKey, only known in process.
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	sweepTime time.Time
+}
+
+// New creates a Limiter, its method limits are parsed when it is initialized.
+func New(config *Config) *Limiter {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Limiter{Config: config, buckets: make(map[string]*bucket)}
+}
+
+func (l *Limiter) Init() error {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+	l.gatewayToken = base64.RawURLEncoding.EncodeToString(token)
+
+	methods, err := parseMethodLimits(l.Config.MethodLimits)
+	if err != nil {
+		return err
+	}
+	if l.Config.Burst < 1 {
+		return errors.New("RATELIMIT_BURST must be at least 1")
+	}
+	l.methods = methods
+	return nil
+}
+
+// Allow counts a request of the caller identified by key to the given full method name.
+// Methods with a limit of their own have a bucket of their own, the others share the default one.
+func (l *Limiter) Allow(key, method string) Decision {
+	limit := methodLimit{Rate: l.Config.Rate, Burst: l.Config.Burst}
+	for _, m := range l.methods {
+		if util.MatchMethod(m.Method, method) {
+			limit = m
+			break
+		}
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	id := key + " " + limit.Method
+	b, ok := l.buckets[id]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		l.buckets[id] = b
+	}
+	b.lastSeen = now
+
+	decision := Decision{Allowed: b.limiter.AllowN(now, 1), Limit: limit.Burst}
+	tokens := b.limiter.TokensAt(now)
+	decision.Remaining = int(math.Max(0, math.Floor(tokens)))
+	decision.Reset = untilTokens(float64(limit.Burst)-tokens, limit.Rate)
+	if !decision.Allowed {
+		decision.RetryAfter = untilTokens(1-tokens, limit.Rate)
+	}
+	return decision
+}
+
+// sweep forgets the buckets of the idle callers, at most once per idle timeout.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.sweepTime) < l.Config.IdleTimeout {
+		return
+	}
+	l.sweepTime = now
+	for id, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= l.Config.IdleTimeout {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+// CustomOpts returns the interceptors of the Limiter, to be given to the grpc Server.
+func (l *Limiter) CustomOpts() grpcProvider.CustomOpts {
+	return grpcProvider.CustomOpts{
+		UnaryInterceptor:  []grpc.UnaryServerInterceptor{l.unaryServerInterceptor},
+		StreamInterceptor: []grpc.StreamServerInterceptor{l.streamServerInterceptor},
+	}
+}
+
+func (l *Limiter) unaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	key, ok := l.callerKey(ctx, info.FullMethod)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	decision := l.Allow(key, info.FullMethod)
+	_ = grpc.SetHeader(ctx, decision.Metadata())
+	if err := decision.Err(); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (l *Limiter) streamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	key, ok := l.callerKey(stream.Context(), info.FullMethod)
+	if !ok {
+		return handler(srv, stream)
+	}
+
+	// Streams are counted once, when they are opened.
+	decision := l.Allow(key, info.FullMethod)
+	_ = stream.SetHeader(decision.Metadata())
+	if err := decision.Err(); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// AllowAddress counts a request of the client at the given address to the given full method, as Allow does.
+// False when the method isn't limited. Used by the gateway, the server only sees its address.
+func (l *Limiter) AllowAddress(addr, method string) (Decision, bool) {
+	if !l.limited(method) {
+		return Decision{}, false
+	}
+	return l.Allow(PeerKey(addr), method), true
+}
+
+// GatewayContext marks a request forwarded by the gateway after AllowAddress counted it,
+// the server doesn't count it again when its caller is anonymous.
+func (l *Limiter) GatewayContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, GatewayMetadataKey, l.gatewayToken)
+}
+
+// fromGateway returns whether the request was marked by GatewayContext.
+func (l *Limiter) fromGateway(ctx context.Context) bool {
+	values := metadata.ValueFromIncomingContext(ctx, GatewayMetadataKey)
+	return len(values) == 1 && len(l.gatewayToken) > 0 && subtle.ConstantTimeCompare([]byte(values[0]), []byte(l.gatewayToken)) == 1
+}
+
+// limited returns whether the requests to the given full method are limited.
+func (l *Limiter) limited(method string) bool {
+	if !l.Config.Enabled {
+		return false
+	}
+	return !util.MatchAnyMethod(l.Config.ExemptMethods, method)
+}
+
+// callerKey identifies the caller of a request, false when the request isn't limited.
+func (l *Limiter) callerKey(ctx context.Context, method string) (string, bool) {
+	if !l.limited(method) {
+		return "", false
+	}
+
+	if principal, ok := auth.FromContext(ctx); ok {
+		if len(principal.ApiKeyID) > 0 {
+			return "apikey:" + principal.ApiKeyID, true
+		}
+		return "sub:" + principal.Subject, true
+	}
+
+	// Anonymous requests forwarded by the gateway were already limited by the address of their client.
+	if l.fromGateway(ctx) {
+		return "", false
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return PeerKey(p.Addr.String()), true
+}
+
+// PeerKey returns the key of an anonymous caller out of its address, with or without port.
+func PeerKey(addr string) string {
+	return "peer:" + hostOf(addr)
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// untilTokens returns how long a bucket refilling at r tokens per second takes to gain n tokens.
+func untilTokens(n, r float64) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	if r <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(n / r * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d >= time.Duration(math.MaxInt64) {
+		return math.MaxInt32
+	}
+	return int(math.Ceil(d.Seconds()))
+}
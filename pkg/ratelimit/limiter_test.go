@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func newTestLimiter(t *testing.T) *Limiter {
+	t.Helper()
+	l := New(&Config{Enabled: true, Rate: 1, Burst: 1})
+	if err := l.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	return l
+}
+
+// loopbackContext returns the context of an anonymous request received from the loopback address, with the given metadata.
+func loopbackContext(md metadata.MD) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4242}})
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+func TestCallerKeyGateway(t *testing.T) {
+	l := newTestLimiter(t)
+	const method = "/platform.v1.ProjectAPI/ListProjects"
+
+	forwarded, _ := metadata.FromOutgoingContext(l.GatewayContext(context.Background()))
+	other := newTestLimiter(t)
+	otherForwarded, _ := metadata.FromOutgoingContext(other.GatewayContext(context.Background()))
+
+	tests := []struct {
+		name    string
+		md      metadata.MD
+		limited bool
+	}{
+		{name: "direct", md: metadata.MD{}, limited: true},
+		{name: "x-forwarded-for", md: metadata.Pairs("x-forwarded-for", "203.0.113.7"), limited: true},
+		{name: "forged gateway token", md: metadata.Pairs(GatewayMetadataKey, "forged"), limited: true},
+		{name: "token of another limiter", md: otherForwarded, limited: true},
+		{name: "forwarded by the gateway", md: forwarded, limited: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, limited := l.callerKey(loopbackContext(tt.md), method)
+			if limited != tt.limited {
+				t.Fatalf("callerKey() limited = %v, want %v", limited, tt.limited)
+			}
+			if limited && key != "peer:127.0.0.1" {
+				t.Errorf("callerKey() = %q, want peer:127.0.0.1", key)
+			}
+		})
+	}
+}
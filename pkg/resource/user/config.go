@@ -1,10 +1,15 @@
 package user
 
-import "github.com/spf13/viper"
+import (
+	"github.com/spf13/viper"
+
+	"learning/grpc-project-service/pkg/tlsconfig"
+)
 
 type Config struct {
 	UserAddr    string
 	MockEnabled bool
+	TLS         *tlsconfig.Config // TLS of the connection, from the USER_TLS_* variables.
 }
 
 const (
@@ -22,5 +27,6 @@ func NewConfigFromEnv() *Config {
 	return &Config{
 		UserAddr:    v.GetString("ADDR"),
 		MockEnabled: v.GetBool("MOCK_ENABLED"),
+		TLS:         tlsconfig.NewConfigFromEnv("USER_TLS"),
 	}
 }
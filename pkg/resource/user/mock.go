@@ -10,6 +10,7 @@ type mockUserResource struct {
 	provider.AbstractProvider
 }
 
+// GetUser pretends every user exists, using the id as display name.
 func (b *mockUserResource) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
-	return new(pb.GetUserResponse), nil
+	return &pb.GetUserResponse{User: &pb.User{Id: req.GetUserId(), Name: req.GetUserId()}}, nil
 }
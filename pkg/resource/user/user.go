@@ -8,10 +8,13 @@ import (
 	grpc_opentracing "github.com/grpc-ecosystem/go-grpc-middleware/tracing/opentracing"
 	"github.com/opentracing/opentracing-go"
 	grpcClient "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 
 	pb "learning/grpc-project-service/api/gen/go/core/v1"
 	"learning/grpc-project-service/pkg/provider"
+	"learning/grpc-project-service/pkg/tenant"
+	"learning/grpc-project-service/pkg/tlsconfig"
 )
 
 type (
@@ -59,6 +62,8 @@ func (b *userResource) Init() error {
 
 	unaryInterceptors := []grpcClient.UnaryClientInterceptor{
 		grpc_opentracing.UnaryClientInterceptor(),
+		// Users are looked for in the tenant of the request.
+		tenant.UnaryClientInterceptor(),
 	}
 
 	// don't use sdk
@@ -67,8 +72,17 @@ func (b *userResource) Init() error {
 	//	grpcSdk.WithServerAddr(b.Config.UserAddr),
 	//	grpcSdk.WithTenantID(utils.GetTenantID()),
 	//)
+	transportCreds := grpcClient.WithInsecure()
+	if b.Config.TLS != nil && b.Config.TLS.Enabled {
+		reloader, err := tlsconfig.NewReloader(b.Config.TLS)
+		if err != nil {
+			return err
+		}
+		transportCreds = grpcClient.WithTransportCredentials(credentials.NewTLS(reloader.ClientConfig()))
+	}
+
 	conn, err := grpcClient.DialContext(ctx, b.Config.UserAddr,
-		grpcClient.WithInsecure(),
+		transportCreds,
 		grpcClient.WithKeepaliveParams(keepalive.ClientParameters{
 			PermitWithoutStream: true,
 		}),
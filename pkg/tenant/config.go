@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"strings"
+
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Config configuration of the tenant Resolver.
+type Config struct {
+	Enabled bool // Whether or not requests must belong to a tenant.
+	// Tenants labelled by name in the metrics even when their requests aren't authenticated.
+	// The tenant of unauthenticated requests comes from their metadata, labelling any of them would let callers
+	// create as many series as they want, so the others are counted as "other".
+	MetricTenants []string
+}
+
+// NewConfigFromEnv initializes the configuration from environment variables or config file.
+func NewConfigFromEnv() *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+
+	v.SetDefault("TENANT_ENABLED", false)
+	v.SetDefault("TENANT_METRIC_TENANTS", "")
+
+	config.LoadFromFile(v)
+
+	enabled := v.GetBool("TENANT_ENABLED")
+
+	metricTenants := make([]string, 0)
+	for _, tenantID := range strings.Split(v.GetString("TENANT_METRIC_TENANTS"), ",") {
+		if tenantID = strings.TrimSpace(tenantID); len(tenantID) > 0 {
+			metricTenants = append(metricTenants, tenantID)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"enabled":       enabled,
+		"metricTenants": metricTenants,
+	}).Debug("Tenant Config Initialized")
+
+	return &Config{
+		Enabled:       enabled,
+		MetricTenants: metricTenants,
+	}
+}
@@ -0,0 +1,54 @@
+package tenant
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type tenantKey struct{}
+
+type unscopedKey struct{}
+
+// NewContext returns a copy of ctx belonging to the tenant, the repository only reaches the data of this tenant.
+func NewContext(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the tenant of ctx, empty when it has none.
+func FromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
+
+// Unscoped returns a copy of ctx reaching the data of all tenants.
+// Only meant for background work across tenants, such as purges, never for a request.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// IsUnscoped returns whether ctx was marked by Unscoped.
+func IsUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// UnaryClientInterceptor sends the tenant of the context to the called services, as the x-tenant-id metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if tenantID := FromContext(ctx); len(tenantID) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataKey, tenantID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// NewOutgoingContext returns a copy of ctx sending tenantID as the x-tenant-id metadata, replacing the value already set.
+// Meant for the gateway, when the tenant is named by the path of a request rather than its headers.
+func NewOutgoingContext(ctx context.Context, tenantID string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(metadataKey, tenantID)
+	return metadata.NewOutgoingContext(ctx, md)
+}
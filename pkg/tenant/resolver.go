@@ -0,0 +1,135 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"learning/grpc-project-service/pkg/auth"
+	"learning/grpc-project-service/pkg/provider"
+	grpcProvider "learning/grpc-project-service/pkg/provider/grpc"
+)
+
+// metadataKey names the tenant of a request, the gateway forwards it from the X-Tenant-Id header.
+const metadataKey = "x-tenant-id"
+
+var validTenantID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// otherTenantLabel labels the requests of the tenants that can't be labelled by name, see Config.MetricTenants.
+const otherTenantLabel = "other"
+
+// handledCounter complements the grpc_prometheus metrics, which can't have a tenant label.
+var handledCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "grpc_server_tenant_handled_total",
+	Help: "Total number of RPCs completed on the server per tenant, regardless of success or failure.",
+}, []string{"tenant", "grpc_method", "grpc_code"})
+
+// Resolver adds the tenant of each request to its context, after the caller was authenticated.
+// Authenticated callers belong to the tenant of their credentials, the x-tenant-id metadata may only repeat it.
+// The metadata alone is trusted when requests aren't authenticated, eg: when a proxy in front of the service sets it.
+type Resolver struct {
+	provider.AbstractProvider
+
+	Config *Config
+}
+
+// New creates a Resolver.
+func New(config *Config) *Resolver {
+	if config == nil {
+		config = NewConfigFromEnv()
+	}
+
+	return &Resolver{Config: config}
+}
+
+// CustomOpts returns the interceptors of the Resolver, to be given to the grpc Server before the ones using the tenant.
+func (r *Resolver) CustomOpts() grpcProvider.CustomOpts {
+	return grpcProvider.CustomOpts{
+		UnaryInterceptor:  []grpc.UnaryServerInterceptor{r.unaryServerInterceptor},
+		StreamInterceptor: []grpc.StreamServerInterceptor{r.streamServerInterceptor},
+	}
+}
+
+func (r *Resolver) unaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !r.Config.Enabled {
+		return handler(ctx, req)
+	}
+
+	ctx, err := r.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	handledCounter.WithLabelValues(r.metricLabel(ctx), info.FullMethod, status.Code(err).String()).Inc()
+	return resp, err
+}
+
+func (r *Resolver) streamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !r.Config.Enabled {
+		return handler(srv, stream)
+	}
+
+	ctx, err := r.resolve(stream.Context())
+	if err != nil {
+		return err
+	}
+	wrapped := grpc_middleware.WrapServerStream(stream)
+	wrapped.WrappedContext = ctx
+	err = handler(srv, wrapped)
+	handledCounter.WithLabelValues(r.metricLabel(ctx), info.FullMethod, status.Code(err).String()).Inc()
+	return err
+}
+
+// resolve returns ctx with the tenant of the request.
+// Unauthenticated requests without metadata, such as health checks, have no tenant and only reach data without one.
+func (r *Resolver) resolve(ctx context.Context) (context.Context, error) {
+	var requested string
+	if values := metadata.ValueFromIncomingContext(ctx, metadataKey); len(values) > 0 {
+		requested = values[0]
+		if !validTenantID.MatchString(requested) {
+			return nil, status.Error(codes.InvalidArgument, "x-tenant-id must be 1 to 64 letters, digits, '.', '_' or '-'")
+		}
+	}
+
+	tenantID := requested
+	if principal, ok := auth.FromContext(ctx); ok {
+		if len(principal.Tenant) == 0 {
+			return nil, status.Error(codes.PermissionDenied, "credentials don't belong to a tenant")
+		}
+		if len(requested) > 0 && requested != principal.Tenant {
+			return nil, status.Error(codes.PermissionDenied, "x-tenant-id doesn't match the tenant of the credentials")
+		}
+		tenantID = principal.Tenant
+	}
+	if len(tenantID) == 0 {
+		return ctx, nil
+	}
+
+	grpc_ctxtags.Extract(ctx).Set("tenant.id", tenantID)
+	return NewContext(ctx, tenantID), nil
+}
+
+// metricLabel returns the tenant label of a request: its tenant when the caller was authenticated, as credentials
+// can't name arbitrary tenants, or when the tenant is one of Config.MetricTenants. Requests without tenant are unlabelled.
+func (r *Resolver) metricLabel(ctx context.Context) string {
+	tenantID := FromContext(ctx)
+	if len(tenantID) == 0 {
+		return ""
+	}
+	if _, ok := auth.FromContext(ctx); ok {
+		return tenantID
+	}
+	for _, metricTenant := range r.Config.MetricTenants {
+		if metricTenant == tenantID {
+			return tenantID
+		}
+	}
+	return otherTenantLabel
+}
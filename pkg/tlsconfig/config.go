@@ -0,0 +1,68 @@
+package tlsconfig
+
+import (
+	"time"
+
+	"learning/grpc-project-service/pkg/util/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultMinVersion     = "1.2"
+	defaultReloadInterval = 10 * time.Second
+)
+
+// Config TLS configuration of a server or client connection.
+type Config struct {
+	Enabled    bool   // Whether or not the connection uses TLS.
+	CertFile   string // PEM certificate chain presented to the peer, optional for clients.
+	KeyFile    string // PEM private key of the certificate.
+	CAFile     string // PEM CAs verifying the peer certificates, the system pool when empty.
+	MinVersion string // Lowest TLS version accepted, eg: 1.2.
+	ClientAuth bool   // Servers only, whether or not clients must present a certificate signed by CAFile (mutual TLS), which must be set.
+	ServerName string // Clients only, name expected in the server certificate when it differs from the dialed host.
+
+	ReloadInterval time.Duration // How often the files are checked for changes, while connections are established.
+}
+
+// NewConfigFromEnv initializes the configuration from the environment variables starting with prefix, eg: GRPC_TLS.
+func NewConfigFromEnv(prefix string) *Config {
+	v := viper.New()
+	v.AutomaticEnv()
+	v.SetEnvPrefix(prefix)
+
+	v.SetDefault("ENABLED", false)
+	v.SetDefault("CERT_FILE", "")
+	v.SetDefault("KEY_FILE", "")
+	v.SetDefault("CA_FILE", "")
+	v.SetDefault("MIN_VERSION", defaultMinVersion)
+	v.SetDefault("CLIENT_AUTH", false)
+	v.SetDefault("SERVER_NAME", "")
+	v.SetDefault("RELOAD_INTERVAL", defaultReloadInterval)
+
+	config.LoadFromFile(v)
+
+	cfg := &Config{
+		Enabled:        v.GetBool("ENABLED"),
+		CertFile:       v.GetString("CERT_FILE"),
+		KeyFile:        v.GetString("KEY_FILE"),
+		CAFile:         v.GetString("CA_FILE"),
+		MinVersion:     v.GetString("MIN_VERSION"),
+		ClientAuth:     v.GetBool("CLIENT_AUTH"),
+		ServerName:     v.GetString("SERVER_NAME"),
+		ReloadInterval: v.GetDuration("RELOAD_INTERVAL"),
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"prefix":     prefix,
+		"enabled":    cfg.Enabled,
+		"certFile":   cfg.CertFile,
+		"caFile":     cfg.CAFile,
+		"minVersion": cfg.MinVersion,
+		"clientAuth": cfg.ClientAuth,
+	}).Debug("TLS Config Initialized")
+
+	return cfg
+}
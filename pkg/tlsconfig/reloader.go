@@ -0,0 +1,186 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Reloader provides the tls.Config of a connection, its certificate and CAs are loaded again when their files change.
+// Files are checked at most once per Config.ReloadInterval while connections are established, so renewed certificates
+// (eg: by cert-manager) are picked up without a restart. The previous ones are kept when the new files are invalid.
+type Reloader struct {
+	config     *Config
+	minVersion uint16
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  []time.Time
+	checkTime time.Time
+}
+
+// NewReloader loads the files of the configuration, it fails when they are invalid.
+func NewReloader(config *Config) (*Reloader, error) {
+	minVersion, ok := versions[config.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("invalid TLS min version %q, use one of 1.0, 1.1, 1.2 or 1.3", config.MinVersion)
+	}
+	if (len(config.CertFile) == 0) != (len(config.KeyFile) == 0) {
+		return nil, errors.New("TLS cert and key files must be set together")
+	}
+	// Without CAs the client certificates would be verified against the system pool, any public certificate would do.
+	if config.ClientAuth && len(config.CAFile) == 0 {
+		return nil, errors.New("TLS client auth needs a CA file to verify the client certificates")
+	}
+
+	r := &Reloader{config: config, minVersion: minVersion}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	return []string{r.config.CertFile, r.config.KeyFile, r.config.CAFile}
+}
+
+// load reads all files, nothing is replaced unless they are all valid.
+func (r *Reloader) load() error {
+	modTimes := make([]time.Time, 0, 3)
+	for _, file := range r.files() {
+		var modTime time.Time
+		if len(file) > 0 {
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			modTime = info.ModTime()
+		}
+		modTimes = append(modTimes, modTime)
+	}
+
+	var cert *tls.Certificate
+	if len(r.config.CertFile) > 0 {
+		c, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+		if err != nil {
+			return fmt.Errorf("invalid TLS certificate %s: %w", r.config.CertFile, err)
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if len(r.config.CAFile) > 0 {
+		raw, err := os.ReadFile(r.config.CAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw) {
+			return fmt.Errorf("invalid TLS CA file %s: no PEM certificate found", r.config.CAFile)
+		}
+	}
+
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	return nil
+}
+
+// current returns the certificate and CAs, reloaded when their files changed since the last check.
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkTime) < r.config.ReloadInterval {
+		return r.cert, r.pool
+	}
+	r.checkTime = time.Now()
+
+	for i, file := range r.files() {
+		if len(file) == 0 {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil && !info.ModTime().Equal(r.modTimes[i]) {
+			if err := r.load(); err != nil {
+				logrus.WithError(err).Error("Could not reload TLS files, using the previous ones")
+			} else {
+				logrus.WithFields(logrus.Fields{"certFile": r.config.CertFile, "caFile": r.config.CAFile}).Info("TLS files reloaded")
+			}
+			break
+		}
+	}
+	return r.cert, r.pool
+}
+
+// ServerConfig returns the tls.Config of a server, requiring client certificates when Config.ClientAuth is set.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			if cert == nil {
+				return nil, errors.New("no TLS certificate configured")
+			}
+			config := &tls.Config{
+				MinVersion:   r.minVersion,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.config.ClientAuth {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+				config.ClientCAs = pool
+			}
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig returns the tls.Config of a client, presenting the certificate when one is configured.
+func (r *Reloader) ClientConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: r.minVersion,
+		ServerName: r.config.ServerName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return new(tls.Certificate), nil
+			}
+			return cert, nil
+		},
+	}
+	if len(r.config.CAFile) == 0 {
+		return config
+	}
+
+	// RootCAs can't change once the config is in use, the chain is verified against the current CAs instead.
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(cs tls.ConnectionState) error {
+		_, pool := r.current()
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("server presented no certificate")
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+			DNSName:       cs.ServerName,
+			Roots:         pool,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+	return config
+}
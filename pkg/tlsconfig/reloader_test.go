@@ -0,0 +1,34 @@
+package tlsconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewReloaderInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		err    string
+	}{
+		{name: "unknown min version", config: &Config{MinVersion: "1.4"}, err: "invalid TLS min version"},
+		{name: "cert without key", config: &Config{MinVersion: "1.2", CertFile: "tls.crt"}, err: "must be set together"},
+		{name: "key without cert", config: &Config{MinVersion: "1.2", KeyFile: "tls.key"}, err: "must be set together"},
+		{name: "client auth without CA", config: &Config{MinVersion: "1.2", ClientAuth: true}, err: "needs a CA file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReloader(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("NewReloader() error = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNewReloaderWithoutFiles(t *testing.T) {
+	if _, err := NewReloader(&Config{MinVersion: "1.2"}); err != nil {
+		t.Errorf("NewReloader() error = %v", err)
+	}
+}
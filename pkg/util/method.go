@@ -0,0 +1,31 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchMethod returns whether the full gRPC method name matches pattern, either exactly or, when pattern ends with /,
+// as a prefix: /package.Service/ matches all methods of the service and / matches every method.
+// Every list of methods of the configuration uses this syntax.
+func MatchMethod(pattern, method string) bool {
+	return pattern == method || (strings.HasSuffix(pattern, "/") && strings.HasPrefix(method, pattern))
+}
+
+// MatchAnyMethod returns whether one of the patterns matches the full method name, see MatchMethod.
+func MatchAnyMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if MatchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateMethodPattern fails for patterns that can't match a full method name, eg: wildcards, which aren't supported.
+func ValidateMethodPattern(pattern string) error {
+	if !strings.HasPrefix(pattern, "/") || strings.Contains(pattern, "*") {
+		return fmt.Errorf("%q isn't a full method name or a /package.Service/ prefix", pattern)
+	}
+	return nil
+}
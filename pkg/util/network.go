@@ -0,0 +1,50 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ErrPrivateAddress returned when a host or an address isn't publicly routable.
+var ErrPrivateAddress = errors.New("private, loopback and link-local addresses are not allowed")
+
+// sharedAddressSpace of carrier-grade NAT, see RFC 6598, not covered by net.IP.IsPrivate.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP returns whether ip is a publicly routable unicast address. Private, loopback, link-local, eg: the
+// 169.254.169.254 metadata endpoint of cloud providers, unspecified, multicast and shared addresses are not.
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip))
+}
+
+// CheckPublicHost fails with ErrPrivateAddress unless all the addresses of host are public, see IsPublicIP.
+func CheckPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return fmt.Errorf("%s resolves to %s: %w", host, addr.IP, ErrPrivateAddress)
+		}
+	}
+	return nil
+}
+
+// PublicOnlyControl is a net.Dialer Control function refusing to connect to addresses that aren't public.
+// It checks the resolved address right before connecting, so a host can't resolve to a public address when it
+// is checked and to a private one when it is dialed.
+func PublicOnlyControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("dial %s %s: %w", network, address, ErrPrivateAddress)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const signaturePrefix = "sha256="
+
+// SignSHA256 returns the signature of a webhook payload, as sent in X-Signature-256 and GitHub's X-Hub-Signature-256 headers: "sha256=<hex HMAC>".
+func SignSHA256(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySHA256 checks a signature returned by SignSHA256 in constant time.
+func VerifySHA256(secret string, payload []byte, signature string) bool {
+	hexMAC, ok := strings.CutPrefix(signature, signaturePrefix)
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(hexMAC)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,98 @@
+package util
+
+import (
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// FieldViolation returns an InvalidArgument error with a google.rpc.BadRequest detail describing the offending field.
+func FieldViolation(field, description string) error {
+	st := status.New(codes.InvalidArgument, description)
+	ds, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: description},
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
+
+// BadRequest returns an InvalidArgument error with a google.rpc.BadRequest detail listing every invalid field.
+// localized is the message in the language of the caller, it's omitted when nil.
+func BadRequest(message string, violations []*errdetails.BadRequest_FieldViolation, localized *errdetails.LocalizedMessage) error {
+	st := status.New(codes.InvalidArgument, message)
+	ds, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err == nil && localized != nil {
+		ds, err = ds.WithDetails(localized)
+	}
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
+
+// SummarizeViolations formats field violations into a single message,
+// eg: "name: value is required; owner_id: value must be a valid UUID.".
+func SummarizeViolations(violations []*errdetails.BadRequest_FieldViolation) string {
+	var b strings.Builder
+	for i, v := range violations {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.Field)
+		b.WriteString(": ")
+		b.WriteString(v.Description)
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+// PreconditionETag type of the precondition violation of a stale etag, the only one the gateway answers with 412.
+const PreconditionETag = "ETAG"
+
+// PreconditionFailure returns a FailedPrecondition error with a google.rpc.PreconditionFailure detail.
+// typ is a service specific violation type, eg: PreconditionETag, subject the resource the precondition is about.
+func PreconditionFailure(typ, subject, description string) error {
+	st := status.New(codes.FailedPrecondition, description)
+	ds, err := st.WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{
+			{Type: typ, Subject: subject, Description: description},
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
+
+// RateLimited returns a ResourceExhausted error with a google.rpc.RetryInfo detail telling when to retry.
+func RateLimited(retryDelay time.Duration, description string) error {
+	st := status.New(codes.ResourceExhausted, description)
+	ds, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
+
+// QuotaFailure returns a ResourceExhausted error with a google.rpc.QuotaFailure detail.
+// subject is what the exhausted quota applies to, eg: "tenants/acme".
+func QuotaFailure(subject, description string) error {
+	st := status.New(codes.ResourceExhausted, description)
+	ds, err := st.WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{
+			{Subject: subject, Description: description},
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return ds.Err()
+}
@@ -9,6 +9,39 @@ func WithID(id uuid.UUID) bson.M {
 	return bson.M{"_id": id}
 }
 
-func WithUpdate(update bson.M) bson.M {
-	return bson.M{"$set": update}
+// WithIDs matches any of the given ids.
+func WithIDs(ids ...uuid.UUID) bson.M {
+	return bson.M{"_id": bson.M{"$in": ids}}
+}
+
+// WithETag restricts the filter to the given revision of a document, an empty etag matches any revision.
+func WithETag(filter bson.M, etag string) bson.M {
+	if len(etag) > 0 {
+		filter["etag"] = etag
+	}
+	return filter
+}
+
+// WithoutDeleted restricts the filter to documents that aren't soft deleted.
+func WithoutDeleted(filter bson.M) bson.M {
+	filter["deleteTime"] = nil
+	return filter
+}
+
+// WithUpdate sets the given fields, the optional unset fields are removed from the document.
+func WithUpdate(update bson.M, unset ...string) bson.M {
+	ret := bson.M{"$set": update}
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, field := range unset {
+			fields[field] = ""
+		}
+		ret["$unset"] = fields
+	}
+	return ret
+}
+
+// WithProjectID matches the documents belonging to a project.
+func WithProjectID(projectID uuid.UUID) bson.M {
+	return bson.M{"projectId": projectID}
 }
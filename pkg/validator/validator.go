@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"learning/grpc-project-service/pkg/provider"
+	grpcProvider "learning/grpc-project-service/pkg/provider/grpc"
+)
+
+// InvalidArgumentFunc converts the violations of a request into the error returned to its caller.
+type InvalidArgumentFunc func(ctx context.Context, violations []*Violation) error
+
+// Validator checks the requests of every gRPC method against the buf.validate annotations of their messages,
+// before they reach the handlers. Messages without annotations are always valid.
+type Validator struct {
+	provider.AbstractProvider
+
+	// Builds the errors of the invalid requests, eg: localized by the controllers.
+	// The violations are returned in English as a google.rpc.BadRequest detail when nil.
+	InvalidArgument InvalidArgumentFunc
+	validator       *protovalidate.Validator
+}
+
+// New creates a Validator, the validation rules of a message are compiled the first time it is received.
+func New() *Validator {
+	return &Validator{}
+}
+
+func (v *Validator) Init() error {
+	validator, err := protovalidate.New()
+	if err != nil {
+		return err
+	}
+	v.validator = validator
+	return nil
+}
+
+// CustomOpts returns the interceptors of the Validator, to be given to the grpc Server after the ones rejecting
+// requests without looking at them, eg: authentication.
+func (v *Validator) CustomOpts() grpcProvider.CustomOpts {
+	return grpcProvider.CustomOpts{
+		UnaryInterceptor:  []grpc.UnaryServerInterceptor{v.unaryServerInterceptor},
+		StreamInterceptor: []grpc.StreamServerInterceptor{v.streamServerInterceptor},
+	}
+}
+
+func (v *Validator) unaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := v.validate(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (v *Validator) streamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &validatedStream{ServerStream: stream, validator: v, method: info.FullMethod})
+}
+
+// validatedStream validates every message received on a stream.
+type validatedStream struct {
+	grpc.ServerStream
+	validator *Validator
+	method    string
+}
+
+func (s *validatedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.validator.validate(s.Context(), s.method, m)
+}
+
+// validate returns the error of Validator.InvalidArgument when req breaks its constraints.
+func (v *Validator) validate(ctx context.Context, method string, req interface{}) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	err := v.validator.Validate(msg)
+	if err == nil {
+		return nil
+	}
+
+	var verr *protovalidate.ValidationError
+	if !errors.As(err, &verr) {
+		// The constraints of the message can't be compiled or evaluated, the annotations are wrong.
+		logrus.WithError(err).WithField("method", method).Error("Could not validate request")
+		return status.Error(codes.Internal, "request could not be validated")
+	}
+
+	invalidArgument := v.InvalidArgument
+	if invalidArgument == nil {
+		invalidArgument = englishInvalidArgument
+	}
+	return invalidArgument(ctx, newViolations(msg, verr.Violations))
+}
@@ -0,0 +1,160 @@
+package validator
+
+import (
+	"context"
+	"strings"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"learning/grpc-project-service/pkg/util"
+)
+
+// Violation a constraint broken by a field of a request.
+type Violation struct {
+	Field        string                 // Proto field path, eg: requests[1].owner_id.
+	ConstraintID string                 // Id of the constraint, eg: string.max_len, or of a custom expression.
+	Message      string                 // English description of the violation.
+	Params       map[string]interface{} // Rules of the violated constraint by name, eg: max_len. Empty when it has none.
+}
+
+// newViolations describes the violations of the constraints of msg, along with the rules they broke.
+func newViolations(msg proto.Message, violations []*validate.Violation) []*Violation {
+	desc := msg.ProtoReflect().Descriptor()
+	result := make([]*Violation, 0, len(violations))
+	for _, violation := range violations {
+		result = append(result, &Violation{
+			Field:        violation.GetFieldPath(),
+			ConstraintID: violation.GetConstraintId(),
+			Message:      violation.GetMessage(),
+			Params:       constraintParams(desc, violation),
+		})
+	}
+	return result
+}
+
+// englishInvalidArgument returns the violations in English as a google.rpc.BadRequest detail, when the Validator
+// has no InvalidArgument function.
+func englishInvalidArgument(_ context.Context, violations []*Violation) error {
+	fields := make([]*errdetails.BadRequest_FieldViolation, 0, len(violations))
+	for _, violation := range violations {
+		fields = append(fields, &errdetails.BadRequest_FieldViolation{Field: violation.Field, Description: violation.Message})
+	}
+	return util.BadRequest(util.SummarizeViolations(fields), fields, nil)
+}
+
+// constraintParams returns the rules of the violated constraint by name, eg: max_len for string.max_len.
+// Constraints that aren't standard rules, eg: required or custom expressions, have none.
+func constraintParams(desc protoreflect.MessageDescriptor, violation *validate.Violation) map[string]interface{} {
+	params := make(map[string]interface{})
+
+	kind, _, ok := strings.Cut(violation.GetConstraintId(), ".")
+	constraints := fieldConstraints(desc, violation.GetFieldPath(), violation.GetForKey())
+	if !ok || constraints == nil {
+		return params
+	}
+	field := constraints.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(kind))
+	if field == nil || field.Message() == nil || !constraints.ProtoReflect().Has(field) {
+		return params
+	}
+
+	constraints.ProtoReflect().Get(field).Message().Range(func(rule protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		params[string(rule.Name())] = ruleValue(rule, value)
+		return true
+	})
+	return params
+}
+
+// ruleValue returns the value of a rule for the templates, lists are rendered like protovalidate does, eg: [0, 1].
+func ruleValue(rule protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch {
+	case rule.IsList():
+		list := value.List()
+		values := make([]string, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			values = append(values, list.Get(i).String())
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case rule.Message() != nil:
+		return value.Message().Interface()
+	default:
+		return value.Interface()
+	}
+}
+
+// fieldConstraints returns the constraints of the field at the given path of a message, eg: requests[1].owner_id.
+// The constraints of the items of a repeated field, or of the keys or values of a map, are returned for the paths of its elements.
+func fieldConstraints(desc protoreflect.MessageDescriptor, path string, forKey bool) *validate.FieldConstraints {
+	for {
+		name, element, rest := nextPathSegment(path)
+		field := desc.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			return nil
+		}
+
+		if len(rest) > 0 {
+			if field.IsMap() {
+				desc = field.MapValue().Message()
+			} else {
+				desc = field.Message()
+			}
+			if desc == nil {
+				return nil
+			}
+			path = rest
+			continue
+		}
+
+		constraints, _ := proto.GetExtension(field.Options(), validate.E_Field).(*validate.FieldConstraints)
+		switch {
+		case !element:
+			return constraints
+		case field.IsList():
+			return constraints.GetRepeated().GetItems()
+		case field.IsMap() && forKey:
+			return constraints.GetMap().GetKeys()
+		case field.IsMap():
+			return constraints.GetMap().GetValues()
+		}
+		return nil
+	}
+}
+
+// nextPathSegment splits the name of the first field off a field path, element reports whether the path continues
+// with an index or a map key, eg: labels["a.b"]. rest is the path following the field, without its separator.
+func nextPathSegment(path string) (name string, element bool, rest string) {
+	end := strings.IndexAny(path, ".[")
+	if end < 0 {
+		return path, false, ""
+	}
+	name, path = path[:end], path[end:]
+
+	// Skips the indexes and keys of the field.
+	for strings.HasPrefix(path, "[") {
+		element = true
+		end := closingBracket(path)
+		if end < 0 {
+			return name, element, ""
+		}
+		path = path[end+1:]
+	}
+	return name, element, strings.TrimPrefix(path, ".")
+}
+
+// closingBracket returns the index of the bracket closing the one path starts with, -1 when there is none.
+// Map keys are quoted when they are strings, and may contain brackets.
+func closingBracket(path string) int {
+	quoted := false
+	for i := 1; i < len(path); i++ {
+		switch {
+		case quoted && path[i] == '\\':
+			i++
+		case path[i] == '"':
+			quoted = !quoted
+		case !quoted && path[i] == ']':
+			return i
+		}
+	}
+	return -1
+}